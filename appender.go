@@ -0,0 +1,131 @@
+package astgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// Appender writes a slice variable built across multiple calls of Add. The
+// variable is declared as var name []T on the first call, and the elements
+// are appended to it in chunks of init functions, so that the producers can
+// generate large data without holding all the elements in memory. Appender
+// writes only the declarations; the package clause and the import
+// declarations of Imports should be written by the caller.
+type Appender struct {
+	// ChunkSize is the number of elements appended in an init function.
+	// Zero means 100.
+	ChunkSize int
+
+	w       io.Writer
+	name    string
+	opts    []Option
+	typ     reflect.Type
+	exprs   []ast.Expr
+	imports map[string]bool
+	buf     bytes.Buffer
+}
+
+// NewAppender creates a new Appender writing the variable of name to w.
+func NewAppender(w io.Writer, name string, opts ...Option) *Appender {
+	return &Appender{w: w, name: name, opts: opts}
+}
+
+// Add builds x and appends it to the variable. The chunk of elements is
+// written when the number of elements reaches ChunkSize. All the elements
+// should have the same type.
+func (a *Appender) Add(x any) error {
+	v := reflect.ValueOf(x)
+	if !v.IsValid() {
+		return fmt.Errorf("unexpected nil to append to %s", a.name)
+	}
+	b := newBuilder(a.opts)
+	if a.typ == nil {
+		t, err := b.buildType(v.Type())
+		if err != nil {
+			return err
+		}
+		if err := a.write(&ast.GenDecl{
+			Tok: token.VAR,
+			Specs: []ast.Spec{
+				&ast.ValueSpec{
+					Names: []*ast.Ident{ast.NewIdent(a.name)},
+					Type:  &ast.ArrayType{Elt: t},
+				},
+			},
+		}); err != nil {
+			return err
+		}
+		a.typ = v.Type()
+	} else if v.Type() != a.typ {
+		return fmt.Errorf("unexpected type %s to append to []%s", v.Type(), a.typ)
+	}
+	n, err := b.buildNode(v)
+	if err != nil {
+		return err
+	}
+	for path := range b.imports {
+		if a.imports == nil {
+			a.imports = make(map[string]bool)
+		}
+		a.imports[path] = true
+	}
+	a.exprs = append(a.exprs, n.(ast.Expr))
+	chunkSize := a.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = 100
+	}
+	if len(a.exprs) >= chunkSize {
+		return a.Flush()
+	}
+	return nil
+}
+
+// Flush writes the elements not written yet. Flush should be called after
+// the last call of Add.
+func (a *Appender) Flush() error {
+	if len(a.exprs) == 0 {
+		return nil
+	}
+	a.buf.Reset()
+	fmt.Fprintf(&a.buf, "package p\n\nfunc init() {\n%s = append(%s,\n", a.name, a.name)
+	for _, e := range a.exprs {
+		if err := format.Node(&a.buf, token.NewFileSet(), e); err != nil {
+			return err
+		}
+		a.buf.WriteString(",\n")
+	}
+	a.buf.WriteString(")\n}\n")
+	src, err := format.Source(a.buf.Bytes())
+	if err != nil {
+		return err
+	}
+	a.exprs = a.exprs[:0]
+	_, err = a.w.Write(append([]byte{'\n'}, src[len("package p\n\n"):]...))
+	return err
+}
+
+// Imports returns the sorted import paths required by the elements added.
+func (a *Appender) Imports() []string {
+	paths := make([]string, 0, len(a.imports))
+	for path := range a.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (a *Appender) write(n ast.Node) error {
+	a.buf.Reset()
+	if err := format.Node(&a.buf, token.NewFileSet(), n); err != nil {
+		return err
+	}
+	a.buf.WriteByte('\n')
+	_, err := a.w.Write(a.buf.Bytes())
+	return err
+}
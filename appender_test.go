@@ -0,0 +1,54 @@
+package astgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestAppender(t *testing.T) {
+	type T struct {
+		X int
+		Y *string
+	}
+	var sb strings.Builder
+	a := astgen.NewAppender(&sb, "data")
+	a.ChunkSize = 2
+	for i, s := range []string{"foo", "bar", "baz"} {
+		s := s
+		if err := a.Add(T{X: i, Y: &s}); err != nil {
+			t.Fatalf("should not return error: %s", err)
+		}
+	}
+	if err := a.Flush(); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `var data []T
+
+func init() {
+	data = append(data,
+		(func(f string) T {
+			return T{Y: &f}
+		})("foo"),
+		(func(b string) T {
+			return T{X: 1, Y: &b}
+		})("bar"),
+	)
+}
+
+func init() {
+	data = append(data,
+		(func(b string) T {
+			return T{X: 2, Y: &b}
+		})("baz"),
+	)
+}
+`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+	if err := a.Add(42); err == nil {
+		t.Errorf("should return error on unexpected type")
+	}
+}
@@ -0,0 +1,62 @@
+package astgen
+
+import (
+	"go/ast"
+	"reflect"
+	"strings"
+)
+
+// isAtomic reports whether the type is the atomic type of sync/atomic, like
+// atomic.Int64, atomic.Pointer[T] and atomic.Value, or the pointer of it.
+func isAtomic(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.PkgPath() != "sync/atomic" {
+		return false
+	}
+	switch t.Name() {
+	case "Bool", "Int32", "Int64", "Uint32", "Uint64", "Uintptr", "Value":
+		return true
+	default:
+		return strings.HasPrefix(t.Name(), "Pointer[")
+	}
+}
+
+// buildAtomic builds the atomic value as the function literal which stores
+// the loaded value to a new value, or the pointer of it.
+func (b *builder) buildAtomic(v reflect.Value) (ast.Expr, error) {
+	ptr := v.Kind() == reflect.Ptr
+	if ptr {
+		if v.IsNil() {
+			return &ast.Ident{Name: "nil"}, nil
+		}
+		v = v.Elem()
+	}
+	p, err := pointerOf(v)
+	if err != nil {
+		return nil, err
+	}
+	w := p.MethodByName("Load").Call(nil)[0]
+	var t ast.Expr = b.selector("sync/atomic", v.Type().Name())
+	if strings.HasPrefix(v.Type().Name(), "Pointer[") {
+		et, err := b.buildType(w.Type().Elem())
+		if err != nil {
+			return nil, err
+		}
+		t = &ast.IndexExpr{X: b.selector("sync/atomic", "Pointer"), Index: et}
+	}
+	var args [][]ast.Expr
+	if !w.IsZero() {
+		e, err := b.buildExpr(w)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, []ast.Expr{unwrapInterface(e)})
+	}
+	var e ast.Expr = storeFunc("v", t, args)
+	if !ptr {
+		e = &ast.StarExpr{X: e}
+	}
+	return e, nil
+}
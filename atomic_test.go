@@ -0,0 +1,63 @@
+package astgen_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildAtomic(t *testing.T) {
+	type Config struct {
+		Name string
+	}
+	type Stats struct {
+		Hits    atomic.Int64
+		Ready   atomic.Bool
+		Misses  *atomic.Uint32
+		Config  atomic.Pointer[Config]
+		Latency atomic.Value
+		Empty   atomic.Value
+		count   atomic.Int32
+	}
+	src := &Stats{Misses: new(atomic.Uint32)}
+	src.Hits.Store(42)
+	src.Ready.Store(true)
+	src.Misses.Store(3)
+	src.Config.Store(&Config{Name: "foo"})
+	src.Latency.Store(time.Second)
+	src.count.Store(-1)
+	got, err := astgen.Source(src, astgen.WithCurrentPackage("example.com/p"))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `&astgen_test.Stats{Hits: *func() *atomic.Int64 {
+	v := new(atomic.Int64)
+	v.Store(int64(42))
+	return v
+}(), Ready: *func() *atomic.Bool {
+	v := new(atomic.Bool)
+	v.Store(true)
+	return v
+}(), Misses: func() *atomic.Uint32 {
+	v := new(atomic.Uint32)
+	v.Store(uint32(3))
+	return v
+}(), Config: *func() *atomic.Pointer[astgen_test.Config] {
+	v := new(atomic.Pointer[astgen_test.Config])
+	v.Store(&astgen_test.Config{Name: "foo"})
+	return v
+}(), Latency: *func() *atomic.Value {
+	v := new(atomic.Value)
+	v.Store(time.Second)
+	return v
+}(), count: *func() *atomic.Int32 {
+	v := new(atomic.Int32)
+	v.Store(int32(-1))
+	return v
+}()}`
+	if string(got) != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, got)
+	}
+}
@@ -0,0 +1,108 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+func init() {
+	for _, x := range []any{(*big.Int)(nil), (*big.Rat)(nil), (*big.Float)(nil)} {
+		t := reflect.TypeOf(x)
+		builtinRules[t] = (*builder).buildBig
+		builtinRules[t.Elem()] = func(b *builder, v reflect.Value) (ast.Expr, error) {
+			if !v.CanAddr() {
+				w := reflect.New(v.Type())
+				w.Elem().Set(v)
+				v = w.Elem()
+			}
+			e, err := b.buildBig(v.Addr())
+			if err != nil {
+				return nil, err
+			}
+			return &ast.StarExpr{X: e}, nil
+		}
+	}
+}
+
+// buildBig builds the big number, like big.NewInt(42) and big.NewRat(1, 3),
+// or parses the exact text of the number if it does not fit in the arguments.
+func (b *builder) buildBig(v reflect.Value) (ast.Expr, error) {
+	if v.IsNil() {
+		return &ast.Ident{Name: "nil"}, nil
+	}
+	switch x := v.Interface().(type) {
+	case *big.Int:
+		if x.IsInt64() {
+			return b.bigCall("NewInt", intLit(x.Int64())), nil
+		}
+		return b.bigParse("Int", 2, &ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: b.bigNew("Int"), Sel: &ast.Ident{Name: "SetString"}},
+			Args: []ast.Expr{stringLit(strconv.Quote(x.String())), intLit(10)},
+		}), nil
+	case *big.Rat:
+		if x.Num().IsInt64() && x.Denom().IsInt64() {
+			return b.bigCall("NewRat", intLit(x.Num().Int64()), intLit(x.Denom().Int64())), nil
+		}
+		return b.bigParse("Rat", 2, &ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: b.bigNew("Rat"), Sel: &ast.Ident{Name: "SetString"}},
+			Args: []ast.Expr{stringLit(strconv.Quote(x.String()))},
+		}), nil
+	case *big.Float:
+		if x.Prec() == 0 {
+			return b.bigNew("Float"), nil
+		}
+		if f, acc := x.Float64(); acc == big.Exact && !x.IsInf() &&
+			x.Prec() == 53 && x.Mode() == big.ToNearestEven {
+			return b.bigCall("NewFloat", &ast.BasicLit{
+				Kind: token.FLOAT, Value: strconv.FormatFloat(f, 'g', -1, 64),
+			}), nil
+		}
+		return b.bigParse("Float", 3, b.bigCall("ParseFloat",
+			stringLit(strconv.Quote(x.Text('p', 0))), intLit(0), intLit(int64(x.Prec())),
+			b.selector("math/big", x.Mode().String()))), nil
+	default:
+		return nil, &UnsupportedTypeError{Type: v.Type()}
+	}
+}
+
+func (b *builder) bigCall(name string, args ...ast.Expr) ast.Expr {
+	return &ast.CallExpr{Fun: b.selector("math/big", name), Args: args}
+}
+
+func (b *builder) bigNew(name string) ast.Expr {
+	return &ast.CallExpr{
+		Fun:  &ast.Ident{Name: "new"},
+		Args: []ast.Expr{b.selector("math/big", name)},
+	}
+}
+
+// bigParse builds the function literal returning the first result of the
+// parse, which is exact by the text of the number, like
+//
+//	func() *big.Int {
+//		x, _ := new(big.Int).SetString("18446744073709551616", 10)
+//		return x
+//	}()
+func (b *builder) bigParse(name string, results int, parse ast.Expr) ast.Expr {
+	lhs := []ast.Expr{&ast.Ident{Name: "x"}}
+	for i := 1; i < results; i++ {
+		lhs = append(lhs, &ast.Ident{Name: "_"})
+	}
+	return &ast.CallExpr{
+		Fun: &ast.FuncLit{
+			Type: &ast.FuncType{
+				Params: &ast.FieldList{},
+				Results: &ast.FieldList{List: []*ast.Field{
+					{Type: &ast.StarExpr{X: b.selector("math/big", name)}},
+				}},
+			},
+			Body: &ast.BlockStmt{List: []ast.Stmt{
+				&ast.AssignStmt{Lhs: lhs, Tok: token.DEFINE, Rhs: []ast.Expr{parse}},
+				&ast.ReturnStmt{Results: []ast.Expr{&ast.Ident{Name: "x"}}},
+			}},
+		},
+	}
+}
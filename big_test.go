@@ -0,0 +1,83 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildBig(t *testing.T) {
+	large, _ := new(big.Int).SetString("18446744073709551616", 10)
+	type T struct {
+		I big.Int
+		R *big.Rat
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "int",
+			src:      []*big.Int{big.NewInt(-42), nil},
+			expected: `[]*big.Int{big.NewInt(-42), nil}`,
+		},
+		{
+			name: "large int",
+			src:  large,
+			expected: `func() *big.Int {
+	x, _ := new(big.Int).SetString("18446744073709551616", 10)
+	return x
+}()`,
+		},
+		{
+			name:     "rat",
+			src:      big.NewRat(6, -4),
+			expected: `big.NewRat(-3, 2)`,
+		},
+		{
+			name: "large rat",
+			src:  new(big.Rat).SetFrac(large, big.NewInt(3)),
+			expected: `func() *big.Rat {
+	x, _ := new(big.Rat).SetString("18446744073709551616/3")
+	return x
+}()`,
+		},
+		{
+			name:     "float",
+			src:      []*big.Float{big.NewFloat(1.5), big.NewFloat(-2), new(big.Float)},
+			expected: `[]*big.Float{big.NewFloat(1.5), big.NewFloat(-2), new(big.Float)}`,
+		},
+		{
+			name: "precise float",
+			src:  new(big.Float).SetPrec(100).SetMode(big.ToZero).Quo(big.NewFloat(1), big.NewFloat(3)),
+			expected: `func() *big.Float {
+	x, _, _ := big.ParseFloat("0x.aaaaaaaaaaaaaaaaaaaaaaaaap-1", 0, 100, big.ToZero)
+	return x
+}()`,
+		},
+		{
+			name:     "struct",
+			src:      T{I: *big.NewInt(1), R: big.NewRat(1, 2)},
+			expected: `astgen_test.T{I: *big.NewInt(1), R: big.NewRat(1, 2)}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, astgen.WithCurrentPackage("example.com/p"))
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package astgen
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+)
+
+// SizeBudget configures building the large strings and byte slices, and
+// limits the number of the elements of the other slices, arrays and maps.
+type SizeBudget struct {
+	// Node is the maximum size of a string or byte slice built as a literal.
+	Node int
+	// Total is the maximum total size of the strings and byte slices built as
+	// literals. Once the total size exceeds the budget, the rest are built by
+	// the Build function.
+	Total int
+	// Build builds the data exceeding the budget as an expression of []byte.
+	// For example, it can write the data to an external file and return the
+	// identifier of the variable with the //go:embed directive. If Build is
+	// nil, the data is embedded compressed with gzip and decompressed at
+	// runtime.
+	Build func(data []byte) (ast.Expr, error)
+	// Base64 makes Build embed the data base64-encoded without compression,
+	// decoded by a helper function like mustDecodeBase64("..."), which is
+	// faster to decode than the compressed data for the incompressible data.
+	Base64 bool
+	// Elems is the maximum number of the elements of a slice, array or map
+	// other than the byte slices. These values cannot be built in the other
+	// representations, so Build returns ElemBudgetError on the value
+	// exceeding the budget.
+	Elems int
+}
+
+// WithSizeBudget makes Build keep the strings and byte slices within the
+// budget as literals, and build the others by the Build function of the
+// budget. The slices, arrays and maps exceeding the element budget are
+// rejected. The zero budgets are unlimited.
+func WithSizeBudget(budget SizeBudget) Option {
+	return func(b *builder) {
+		b.budget = &budget
+	}
+}
+
+// overBudget reports whether the data of the size exceeds the budget, or
+// consumes the total budget.
+func (b *builder) overBudget(size int) bool {
+	if b.budget == nil {
+		return false
+	}
+	if b.budget.Node > 0 && size > b.budget.Node ||
+		b.budget.Total > 0 && b.budgetUsed+size > b.budget.Total {
+		return true
+	}
+	b.budgetUsed += size
+	return false
+}
+
+// checkElems returns ElemBudgetError if the number of the elements of the
+// slice, array or map exceeds the budget. The byte slices are limited by the
+// size budgets instead.
+func (b *builder) checkElems(v reflect.Value) error {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return nil
+	}
+	if b.budget != nil && b.budget.Elems > 0 && v.Len() > b.budget.Elems {
+		return &ElemBudgetError{Type: v.Type(), Len: v.Len(), Elems: b.budget.Elems}
+	}
+	return nil
+}
+
+// ElemBudgetError is the error of the slice, array or map having the elements
+// more than the budget configured by SizeBudget.Elems.
+type ElemBudgetError struct {
+	Type  reflect.Type
+	Len   int
+	Elems int
+}
+
+func (err *ElemBudgetError) Error() string {
+	return fmt.Sprintf("%d elements of %s exceed the budget %d", err.Len, err.Type, err.Elems)
+}
+
+// buildLargeData builds the string or byte slice exceeding the budget.
+func (b *builder) buildLargeData(v reflect.Value, data []byte) (ast.Expr, error) {
+	var e ast.Expr
+	if b.budget.Build != nil {
+		var err error
+		if e, err = b.budget.Build(data); err != nil {
+			return nil, err
+		}
+	} else if b.budget.Base64 {
+		e = b.buildBase64Data(data)
+	} else {
+		e = b.buildGzipData(data)
+	}
+	if v.Type() == reflect.TypeOf([]byte(nil)) {
+		return e, nil
+	}
+	t, err := b.buildType(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	return &ast.CallExpr{Fun: t, Args: []ast.Expr{e}}, nil
+}
+
+// gzipDataTemplate is the expression decompressing the base64-encoded, gzip
+// compressed data at runtime.
+const gzipDataTemplate = `func() []byte {
+	r, err := gzip.NewReader(base64.NewDecoder(base64.StdEncoding, strings.NewReader(DATA)))
+	if err != nil {
+		panic(err)
+	}
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}()`
+
+func (b *builder) buildGzipData(data []byte) ast.Expr {
+	var buf bytes.Buffer
+	w, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	w.Write(data)
+	w.Close()
+	for _, path := range []string{"compress/gzip", "encoding/base64", "io", "strings"} {
+		b.addImport(path)
+	}
+	return parseTemplate(gzipDataTemplate, map[string]ast.Expr{
+		"DATA": stringLit(strconv.Quote(base64.StdEncoding.EncodeToString(buf.Bytes()))),
+	})
+}
+
+const base64HelperTemplate = `func(s string) []byte {
+	bs, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}`
+
+func (b *builder) buildBase64Data(data []byte) ast.Expr {
+	b.addImport("encoding/base64")
+	fn := parseTemplate(base64HelperTemplate, nil)
+	ft := parseTemplate("func(string) []byte", nil)
+	return &ast.CallExpr{
+		Fun:  b.getHelperIdent("mustDecodeBase64", ft, fn),
+		Args: []ast.Expr{stringLit(strconv.Quote(base64.StdEncoding.EncodeToString(data)))},
+	}
+}
+
+// parseTemplate parses the template expression and replaces the identifiers
+// with the expressions. The positions are cleared to print the expression
+// along with the other nodes.
+func parseTemplate(src string, exprs map[string]ast.Expr) ast.Expr {
+	e, err := parser.ParseExprFrom(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err != nil {
+		panic(err)
+	}
+	clearPositions(e)
+	ast.Inspect(e, func(n ast.Node) bool {
+		if n, ok := n.(*ast.CallExpr); ok {
+			for i, arg := range n.Args {
+				if arg, ok := arg.(*ast.Ident); ok && exprs[arg.Name] != nil {
+					n.Args[i] = exprs[arg.Name]
+				}
+			}
+		}
+		return true
+	})
+	return e
+}
@@ -0,0 +1,183 @@
+package astgen_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithSizeBudget(t *testing.T) {
+	type S struct {
+		X string
+		Y []byte
+		Z []string
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		budget   astgen.SizeBudget
+		expected string
+	}{
+		{
+			name:     "within budget",
+			src:      S{X: "foo", Y: []byte("bar")},
+			budget:   astgen.SizeBudget{Node: 3, Total: 6},
+			expected: `S{X: "foo", Y: []uint8{uint8(98), uint8(97), uint8(114)}}`,
+		},
+		{
+			name:     "node budget",
+			src:      S{X: "foo", Y: []byte("barbaz"), Z: []string{"qux", "quux"}},
+			budget:   astgen.SizeBudget{Node: 3},
+			expected: `S{X: "foo", Y: data6, Z: []string{"qux", string(data4)}}`,
+		},
+		{
+			name:     "total budget",
+			src:      S{X: "foo", Y: []byte("barbaz"), Z: []string{"qux", "quux"}},
+			budget:   astgen.SizeBudget{Total: 7},
+			expected: `S{X: "foo", Y: data6, Z: []string{"qux", string(data4)}}`,
+		},
+		{
+			name:     "elements budget",
+			src:      S{Y: []byte("barbaz"), Z: []string{"qux", "quux"}},
+			budget:   astgen.SizeBudget{Elems: 2},
+			expected: `S{Y: []uint8{uint8(98), uint8(97), uint8(114), uint8(98), uint8(97), uint8(122)}, Z: []string{"qux", "quux"}}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.budget.Build = func(data []byte) (ast.Expr, error) {
+				return ast.NewIdent(fmt.Sprintf("data%d", len(data))), nil
+			}
+			got, err := astgen.Build(tc.src, astgen.WithSizeBudget(tc.budget))
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildWithSizeBudgetCompressed(t *testing.T) {
+	type T string
+	src := strings.Repeat("Hello, world! ", 100)
+	got, err := astgen.Build(T(src), astgen.WithSizeBudget(astgen.SizeBudget{Node: 100}))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	if !strings.HasPrefix(sb.String(), "T(func() []byte {\n\tr, err := gzip.NewReader(") {
+		t.Fatalf("should build compressed data: %s", sb.String())
+	}
+	var lit *ast.BasicLit
+	ast.Inspect(got, func(n ast.Node) bool {
+		if n, ok := n.(*ast.BasicLit); ok && n.Kind == token.STRING {
+			lit = n
+		}
+		return true
+	})
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if len(s) >= len(src) {
+		t.Errorf("should be compressed: %d >= %d", len(s), len(src))
+	}
+	r, err := gzip.NewReader(base64.NewDecoder(base64.StdEncoding, strings.NewReader(s)))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if !bytes.Equal(bs, []byte(src)) {
+		t.Errorf("expected: %s\ngot: %s", src, bs)
+	}
+}
+
+func TestBuildWithSizeBudgetBase64(t *testing.T) {
+	type S struct {
+		X string
+		Y []byte
+		Z []byte
+	}
+	src := S{X: "Hello, world!", Y: []byte{0x1f, 0x8b, 0x08, 0x00, 0xff}, Z: []byte("foo")}
+	fb := astgen.NewFileBuilder("p", astgen.WithSizeBudget(astgen.SizeBudget{Node: 4, Base64: true}))
+	if err := fb.Add("x", src); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	got, err := fb.Source()
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `package p
+
+import "encoding/base64"
+
+var x = S{X: string(mustDecodeBase64("SGVsbG8sIHdvcmxkIQ==")), Y: mustDecodeBase64("H4sIAP8="), Z: []uint8{uint8(102), uint8(111), uint8(111)}}
+
+func mustDecodeBase64(s string) []byte {
+	bs, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+`
+	if string(got) != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, got)
+	}
+}
+
+func TestBuildWithSizeBudgetElemsError(t *testing.T) {
+	type S struct {
+		M map[string]int
+		Z []string
+	}
+	testCases := []struct {
+		name string
+		src  any
+		err  string
+	}{
+		{
+			name: "slice",
+			src:  S{Z: []string{"foo", "bar", "baz"}},
+			err:  "S.Z: 3 elements of []string exceed the budget 2",
+		},
+		{
+			name: "map",
+			src:  S{M: map[string]int{"a": 1, "b": 2, "c": 3}},
+			err:  "S.M: 3 elements of map[string]int exceed the budget 2",
+		},
+		{
+			name: "array",
+			src:  [3]int{},
+			err:  "3 elements of [3]int exceed the budget 2",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := astgen.Build(tc.src, astgen.WithSizeBudget(astgen.SizeBudget{Elems: 2}))
+			var e *astgen.ElemBudgetError
+			if !errors.As(err, &e) || err.Error() != tc.err {
+				t.Errorf("expected: %s\ngot: %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package astgen
 
 import (
-	"fmt"
+	"bytes"
+	"context"
 	"go/ast"
 	"go/printer"
 	"go/token"
+	"math"
 	"reflect"
 	"slices"
 	"strconv"
@@ -12,30 +14,152 @@ import (
 )
 
 // Build ast from any.
-func Build(x any) (ast.Node, error) {
-	return (&builder{}).build(reflect.ValueOf(x))
+func Build(x any, opts ...Option) (ast.Node, error) {
+	return newBuilder(opts).buildNode(reflect.ValueOf(x))
 }
 
+// MustBuild is like Build but panics if x cannot be built. It simplifies the
+// code generators and tests, where the errors are fatal anyway.
+func MustBuild(x any, opts ...Option) ast.Node {
+	n, err := Build(x, opts...)
+	if err != nil {
+		panic("astgen: " + err.Error())
+	}
+	return n
+}
+
+func newBuilder(opts []Option) *builder {
+	b := &builder{}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+func (b *builder) buildNode(v reflect.Value) (ast.Node, error) {
+	var key fingerprint
+	var cacheable bool
+	if b.cache != nil && b.comments == nil {
+		if key, cacheable = fingerprintOf(v); cacheable {
+			if n, meta, ok := b.cache.get(key); ok {
+				b.replayMeta(n, meta)
+				b.position(n)
+				return n, nil
+			}
+		}
+	}
+	n, err := b.build(v)
+	if err != nil {
+		return nil, err
+	}
+	if b.normalize {
+		n = Normalize(n)
+	}
+	for _, fn := range b.postProcess {
+		n = fn(n)
+	}
+	if cacheable {
+		b.cache.put(key, n, b.cacheMeta(n))
+	}
+	b.position(n)
+	return n, nil
+}
+
+// Option configures Build.
+type Option func(*builder)
+
 type builder struct {
-	vars []builderVar
+	normalize   bool
+	cache       *Cache
+	msgpackExts map[int8]func([]byte) (any, error)
+	cborTags    map[uint64]func(any) (any, error)
+	budget      *SizeBudget
+	commentTag  string
+	zeroFunc    func(reflect.Value) bool
+	defaults    map[reflect.Type]reflect.Value
+	fieldFilter func(reflect.StructField, reflect.Value) bool
+	rules       map[reflect.Type]func(reflect.Value) (ast.Expr, error)
+	beforeValue func(reflect.Value) (reflect.Value, error)
+	afterExpr   func(reflect.Value, ast.Expr) (ast.Expr, error)
+	postProcess []func(ast.Node) ast.Node
+	maxDepth    int
+	maxNodes    int
+	assign      bool
+	lineWidth   int
+	fileSet     *token.FileSet
+	generatedBy string
+	goGenerate  string
+	commentFunc func(string, reflect.Value) string
+	pairs       bool
+	setHelper   bool
+	makeChan    bool
+	funcs       map[uintptr]ast.Expr
+	funcNames   bool
+	unsupZero   bool
+	unsupWarn   func(string, reflect.Value)
+	unsafePtr   UnsafePointerMode
+	ptrHelpers  bool
+	ptrHelper   string
+	bytesString bool
+	bytesHex    bool
+	hexPerLine  int
+	textHelper  bool
+	syncMap     SyncMapMode
+	errorValues bool
+	nameGen     NameGenerator
+	namePrefix  string
+	qualify     bool
+	qualifier   Qualifier
+	typeAliases map[reflect.Type]ast.Expr
+	currentPkg  string
+	fallback    bool
+	unexported  UnexportedMode
+	unexpTypes  map[reflect.Type]UnexportedMode
+	jsonNumber  JSONNumberMode
+	jsonBigInt  JSONBigIntMode
+
+	ctx        context.Context
+	steps      int
+	nodes      int
+	scratch    bool
+	depth      int
+	deepest    int
+	budgetUsed int
+	comments   map[ast.Node]string
+	path       []string
+	zeros      map[zeroKey]ast.Expr
+	elide      bool
+	imports    map[string]bool
+	rowLits    map[*ast.CompositeLit]int
+	ptrCalls   map[*ast.Ident]bool
+	ruleValues map[string]reflect.Value
+	vars       []builderVar
+	varNames   map[string]bool
+	fset       *token.FileSet
+	buf        bytes.Buffer
 }
 
 type builderVar struct {
-	name   string
+	ident  *ast.Ident
+	base   string
+	exact  bool
 	typ    ast.Expr
 	expr   ast.Expr
-	varptr bool
+	varptr bool // refers to the other variables
+	helper bool // helper function
+	refs   int  // number of the references
 }
 
 func (b *builder) build(v reflect.Value) (ast.Node, error) {
-	n, err := b.buildExpr(v)
+	v, err := b.sortedPairs(v)
 	if err != nil {
 		return nil, err
 	}
-	if len(b.vars) == 0 {
-		return n, nil
+	n, err := b.buildWithVars(v)
+	if err != nil || len(b.vars) == 0 {
+		return n, err
 	}
-	t, err := buildType(v.Type())
+	t, err := b.buildType(v.Type())
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +171,7 @@ func (b *builder) build(v reflect.Value) (ast.Node, error) {
 		if bv.varptr {
 			body = append(body, &ast.AssignStmt{
 				Tok: token.DEFINE,
-				Lhs: []ast.Expr{&ast.Ident{Name: bv.name}},
+				Lhs: []ast.Expr{bv.ident},
 				Rhs: []ast.Expr{bv.expr},
 			})
 			continue
@@ -55,14 +179,13 @@ func (b *builder) build(v reflect.Value) (ast.Node, error) {
 		args = append(args, bv.expr)
 		if i > 0 && reflect.DeepEqual(prevType, bv.typ) {
 			params[len(params)-1].Names = append(
-				params[len(params)-1].Names,
-				&ast.Ident{Name: bv.name},
+				params[len(params)-1].Names, bv.ident,
 			)
 			continue
 		}
 		prevType = bv.typ
 		params = append(params, &ast.Field{
-			Names: []*ast.Ident{{Name: bv.name}},
+			Names: []*ast.Ident{bv.ident},
 			Type:  bv.typ,
 		})
 	}
@@ -86,7 +209,108 @@ func (b *builder) build(v reflect.Value) (ast.Node, error) {
 	}, nil
 }
 
+// buildWithVars builds the expression referring to the helper variables,
+// which are named not to collide with the identifiers in the expression and
+// the names.
+func (b *builder) buildWithVars(v reflect.Value, names ...string) (ast.Expr, error) {
+	b.rootPath(v)
+	n, err := b.buildExpr(v)
+	if err != nil {
+		return nil, withRoot(err, v.Type())
+	}
+	if err := b.checkGenericPtrHelper(n); err != nil {
+		return nil, err
+	}
+	if len(b.vars) > 0 {
+		b.resolveVarNames(names, n)
+	}
+	return n, nil
+}
+
+// stackSegmentDepth is the depth of nesting built on one goroutine. Beyond
+// this depth, building continues on a new goroutine with a fresh stack, so
+// the depth of nesting is not limited by the maximum stack size.
+const stackSegmentDepth = 10000
+
 func (b *builder) buildExpr(v reflect.Value) (ast.Expr, error) {
+	if err := b.checkContext(); err != nil {
+		return nil, err
+	}
+	b.depth++
+	b.deepest = max(b.deepest, b.depth)
+	defer func() { b.depth-- }()
+	if b.maxDepth > 0 && b.depth > b.maxDepth {
+		return nil, &DepthLimitError{b.maxDepth}
+	}
+	if err := b.checkNodes(1); err != nil {
+		return nil, err
+	}
+	b.nodes++
+	if b.depth%stackSegmentDepth != 0 {
+		return b.buildValue(v)
+	}
+	var e ast.Expr
+	var err error
+	var p any
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { p = recover() }()
+		e, err = b.buildValue(v)
+	}()
+	<-done
+	if p != nil {
+		panic(p)
+	}
+	return e, err
+}
+
+// buildValue builds the value calling the hooks, except for the scratch
+// expressions, which are not the parts of the result.
+func (b *builder) buildValue(v reflect.Value) (ast.Expr, error) {
+	hooked := !b.scratch
+	if hooked && b.beforeValue != nil {
+		var err error
+		if v, err = b.beforeValue(v); err != nil {
+			return nil, err
+		}
+	}
+	var e ast.Expr
+	var err error
+	if (v.Kind() == reflect.Struct || v.Kind() == reflect.Array) && v.IsZero() {
+		e, err = b.buildZero(v, b.elide)
+	} else {
+		e, err = b.buildValueUncached(v)
+	}
+	if err != nil || !hooked || b.afterExpr == nil {
+		return e, err
+	}
+	return b.afterExpr(v, e)
+}
+
+func (b *builder) buildValueUncached(v reflect.Value) (ast.Expr, error) {
+	elide := b.elide
+	b.elide = false
+	if v.IsValid() {
+		if r, ok := b.rules[v.Type()]; ok {
+			return r(v)
+		}
+		if e, ok, err := marshalAST(v); ok {
+			return e, err
+		}
+		if t, keys, values, ok, err := orderedMap(v); ok {
+			if err != nil {
+				return nil, err
+			}
+			return b.buildOrderedMap(t, keys, values, elide && v.Kind() == reflect.Map)
+		}
+		if e, ok, err := b.buildBuiltin(v); ok {
+			if err == nil && b.ruleValues != nil && v.CanInterface() {
+				b.ruleValues[printNode(e)] = v
+			}
+			return e, err
+		}
+	}
 	switch v.Kind() {
 	case reflect.Invalid:
 		return &ast.Ident{Name: "nil"}, nil
@@ -96,122 +320,325 @@ func (b *builder) buildExpr(v reflect.Value) (ast.Expr, error) {
 		}
 		return &ast.Ident{Name: "false"}, nil
 	case reflect.Int:
-		return &ast.BasicLit{Kind: token.INT, Value: fmt.Sprint(v.Int())}, nil
+		return &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(v.Int(), 10)}, nil
 	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return callExpr(token.INT, v.Type().Name(), fmt.Sprint(v.Int())), nil
+		return callExpr(token.INT, b.typeName(v.Type()), strconv.FormatInt(v.Int(), 10)), nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return callExpr(token.INT, v.Type().Name(), fmt.Sprint(v.Uint())), nil
+		return callExpr(token.INT, b.typeName(v.Type()), strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Uintptr:
+		return buildUintptr(b.typeName(v.Type()), v.Uint()), nil
 	case reflect.Float32:
-		return callExpr(token.FLOAT, "float32", fmt.Sprint(v.Float())), nil
+		if e := b.buildNonFinite(v.Float()); e != nil {
+			return &ast.CallExpr{Fun: b.typeName(v.Type()), Args: []ast.Expr{e}}, nil
+		}
+		return callExpr(token.FLOAT, b.typeName(v.Type()), strconv.FormatFloat(v.Float(), 'g', -1, 32)), nil
 	case reflect.Float64:
-		s := fmt.Sprint(v.Float())
-		if !strings.ContainsRune(s, '.') {
+		if e := b.buildNonFinite(v.Float()); e != nil {
+			if v.Type().PkgPath() != "" {
+				e = &ast.CallExpr{Fun: b.typeName(v.Type()), Args: []ast.Expr{e}}
+			}
+			return e, nil
+		}
+		s := strconv.FormatFloat(v.Float(), 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
 			s += ".0"
 		}
 		return &ast.BasicLit{Kind: token.FLOAT, Value: s}, nil
 	case reflect.Complex64, reflect.Complex128:
-		return callExpr(token.FLOAT, v.Type().Name(), fmt.Sprint(v.Complex())), nil
-	case reflect.String:
-		if strings.ContainsRune(v.String(), '"') && !strings.ContainsRune(v.String(), '`') {
-			s := strings.ReplaceAll(v.String(), `"`, "")
-			if len(strconv.Quote(s)) == len(s)+2 { // check no escape characters
-				return &ast.BasicLit{Kind: token.STRING, Value: "`" + v.String() + "`"}, nil
+		if e := b.buildNonFiniteComplex(v.Complex(), int(v.Type().Size())*4); e != nil {
+			if v.Kind() == reflect.Complex64 || v.Type().PkgPath() != "" {
+				e = &ast.CallExpr{Fun: b.typeName(v.Type()), Args: []ast.Expr{e}}
 			}
+			return e, nil
+		}
+		return callExpr(token.FLOAT, b.typeName(v.Type()),
+			strconv.FormatComplex(v.Complex(), 'g', -1, int(v.Type().Size())*8)), nil
+	case reflect.String:
+		if b.overBudget(v.Len()) {
+			return b.buildLargeData(v, []byte(v.String()))
 		}
-		return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(v.String())}, nil
+		return quoteString(v.String()), nil
 	case reflect.Interface:
-		e, err := b.buildExpr(v.Elem())
+		w, err := b.sortedPairs(v.Elem())
 		if err != nil {
 			return nil, err
 		}
-		t, err := buildType(v.Type())
+		e, err := b.buildExpr(w)
+		if err != nil {
+			return nil, err
+		}
+		if id, ok := e.(*ast.Ident); ok && id.Name == "nil" && w.IsValid() { // keep the type of nil
+			t, err := b.buildType(w.Type())
+			if err != nil {
+				return nil, err
+			}
+			e = &ast.CallExpr{Fun: &ast.ParenExpr{X: t}, Args: []ast.Expr{e}}
+		} else if w.IsValid() && isDefinedLit(w.Type(), e) { // keep the defined type of the literal
+			e = &ast.CallExpr{Fun: b.typeName(w.Type()), Args: []ast.Expr{e}}
+		}
+		if w.IsValid() && interfaceResults[w.Type()] == v.Type() {
+			return e, nil
+		}
+		t, err := b.buildType(v.Type())
 		if err != nil {
 			return nil, err
 		}
 		return &ast.CallExpr{Fun: t, Args: []ast.Expr{e}}, nil
 	case reflect.Array, reflect.Slice:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 &&
+			!v.IsNil() && b.overBudget(v.Len()) {
+			return b.buildLargeData(v, v.Bytes())
+		}
+		if b.bytesString && v.Kind() == reflect.Slice && v.Type().Elem() == byteType &&
+			isText(v.Bytes()) {
+			return b.buildBytesString(v)
+		}
+		if b.bytesHex && v.Type().Elem() == byteType {
+			return b.buildBytesHex(v, elide)
+		}
+		if err := b.checkElems(v); err != nil {
+			return nil, err
+		}
+		if err := b.checkNodes(v.Len()); err != nil {
+			return nil, err
+		}
 		exprs := make([]ast.Expr, v.Len())
 		for i := 0; i < v.Len(); i++ {
-			w, err := b.buildExpr(v.Index(i))
+			b.pushPath(v, i)
+			w, err := b.buildElem(v.Index(i))
 			if err != nil {
-				return nil, err
+				return nil, wrapPath(err, indexSegment(i))
 			}
-			exprs[i] = dropLitType(w)
+			b.popPath()
+			exprs[i] = w
 		}
-		t, err := buildType(v.Type())
+		t, err := b.buildLitType(v.Type(), elide)
 		if err != nil {
 			return nil, err
 		}
 		return &ast.CompositeLit{Type: t, Elts: exprs}, nil
 	case reflect.Map:
-		type keyExpr struct {
-			value reflect.Value
-			expr  ast.Expr
-			str   string
-		}
-		keys := make([]keyExpr, v.Len())
-		for i, key := range v.MapKeys() {
-			expr, err := b.buildExpr(key)
-			if err != nil {
-				return nil, err
-			}
-			var sb strings.Builder
-			printer.Fprint(&sb, token.NewFileSet(), expr)
-			keys[i] = keyExpr{value: key, expr: expr, str: sb.String()}
+		if err := b.checkElems(v); err != nil {
+			return nil, err
 		}
-		slices.SortFunc(keys, func(k1, k2 keyExpr) int {
-			return strings.Compare(k1.str, k2.str)
-		})
-		exprs := make([]ast.Expr, v.Len())
+		if b.setHelper && isSetType(v.Type()) && v.Len() > 0 {
+			return b.buildSet(v)
+		}
+		if err := b.checkNodes(2 * v.Len()); err != nil {
+			return nil, err
+		}
+		keys, err := b.sortMapKeys(v)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]reflect.Value, len(keys))
 		for i, key := range keys {
-			v, err := b.buildExpr(v.MapIndex(key.value))
-			if err != nil {
-				return nil, err
-			}
-			exprs[i] = &ast.KeyValueExpr{
-				Key:   key.expr,
-				Value: dropLitType(v),
-			}
+			values[i] = v.MapIndex(key)
+		}
+		exprs, err := b.buildMapElts(v, keys, values)
+		if err != nil {
+			return nil, err
 		}
-		t, err := buildType(v.Type())
+		t, err := b.buildLitType(v.Type(), elide)
 		if err != nil {
 			return nil, err
 		}
 		return &ast.CompositeLit{Type: t, Elts: exprs}, nil
 	case reflect.Struct:
+		if b.fallback && b.unexportedMode(v.Type()) == UnexportedKeep &&
+			b.hasInaccessibleFields(v.Type()) && !v.IsZero() {
+			return b.buildFallbackStruct(v)
+		}
 		exprs := make([]ast.Expr, 0, v.NumField())
 		for i := 0; i < v.NumField(); i++ {
-			if isZero(v.Field(i)) {
+			if b.omitField(v, i) {
 				continue
 			}
-			k := &ast.Ident{Name: v.Type().Field(i).Name}
-			v, err := b.buildExpr(v.Field(i))
+			f, fv := v.Type().Field(i), v.Field(i)
+			if skip, err := b.checkUnexported(v.Type(), f); skip || err != nil {
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			k := &ast.Ident{Name: f.Name}
+			b.pushPath(v, f.Name)
+			v, err := b.buildExpr(fv)
 			if err != nil {
-				return nil, err
+				return nil, wrapPath(err, fieldSegment(f.Name))
 			}
-			exprs = append(exprs, &ast.KeyValueExpr{Key: k, Value: v})
+			kv := &ast.KeyValueExpr{Key: k, Value: v}
+			b.fieldComment(kv, f, fv)
+			b.popPath()
+			exprs = append(exprs, kv)
 		}
-		t, err := buildType(v.Type())
+		t, err := b.buildLitType(v.Type(), elide)
 		if err != nil {
 			return nil, err
 		}
 		return &ast.CompositeLit{Type: t, Elts: exprs}, nil
 	case reflect.Ptr:
-		w, err := b.buildExpr(v.Elem())
-		if err != nil {
-			return nil, err
+		if v.IsNil() {
+			return &ast.Ident{Name: "nil"}, nil
 		}
 		switch v.Elem().Kind() {
 		case reflect.Invalid, reflect.Bool, reflect.String, reflect.Interface, reflect.Ptr,
 			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 			reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+			w, err := b.buildExpr(v.Elem())
+			if err != nil {
+				return nil, err
+			}
+			if b.ptrHelper != "" && isBasicKind(v.Elem().Kind()) {
+				return b.buildGenericPtrCall(v.Elem(), w), nil
+			}
+			if b.ptrHelpers && isBasicKind(v.Elem().Kind()) {
+				return b.buildPtrHelperCall(v.Elem(), w)
+			}
+			return b.newPtrExpr(v.Elem(), w)
+		}
+		b.elide = elide
+		w, err := b.buildExpr(v.Elem())
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := w.(*ast.CompositeLit); !ok { // reconstruction code
 			return b.newPtrExpr(v.Elem(), w)
 		}
 		return &ast.UnaryExpr{Op: token.AND, X: w}, nil
+	case reflect.Func:
+		if e, ok := b.buildFunc(v); ok {
+			return e, nil
+		}
+		if b.fallback {
+			return b.buildFallbackFunc(v)
+		}
+		return b.buildUnsupported(v)
+	case reflect.Chan:
+		if b.makeChan {
+			return b.buildChan(v)
+		}
+		return b.buildUnsupported(v)
+	case reflect.UnsafePointer:
+		return b.buildUnsafePointer(v)
 	default:
-		return nil, &unexpectedTypeError{v.Type()}
+		return b.buildUnsupported(v)
+	}
+}
+
+// buildMapElts builds the elements of the map literal of the keys and the
+// values.
+func (b *builder) buildMapElts(v reflect.Value, keys, values []reflect.Value) ([]ast.Expr, error) {
+	exprs := make([]ast.Expr, len(keys))
+	for i, key := range keys {
+		k, err := b.buildExpr(key)
+		if err != nil {
+			return nil, wrapPath(err, keySegment(key))
+		}
+		b.pushPath(v, key)
+		e, err := b.buildElem(values[i])
+		if err != nil {
+			return nil, wrapPath(err, keySegment(key))
+		}
+		b.popPath()
+		exprs[i] = &ast.KeyValueExpr{Key: k, Value: e}
+	}
+	return exprs, nil
+}
+
+// sortMapKeys sorts the keys of the map by scratch expressions, so that the
+// entries are built in the sorted order and the helper variables are
+// registered regardless of the map iteration order.
+func (b *builder) sortMapKeys(v reflect.Value) ([]reflect.Value, error) {
+	type keyExpr struct {
+		value reflect.Value
+		str   string
+	}
+	keys := make([]keyExpr, v.Len())
+	for i, key := range v.MapKeys() {
+		str, err := b.scratchString(key)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = keyExpr{value: key, str: str}
+	}
+	slices.SortFunc(keys, func(k1, k2 keyExpr) int {
+		return strings.Compare(k1.str, k2.str)
+	})
+	for i := 0; i < len(keys); {
+		j := i + 1
+		for j < len(keys) && keys[j].str == keys[i].str {
+			j++
+		}
+		if j-i > 1 { // distinct pointer keys can be printed the same
+			for k := i; k < j; k++ {
+				str, err := b.scratchString(v.MapIndex(keys[k].value))
+				if err != nil {
+					return nil, err
+				}
+				keys[k].str += ":" + str
+			}
+			slices.SortFunc(keys[i:j], func(k1, k2 keyExpr) int {
+				return strings.Compare(k1.str, k2.str)
+			})
+		}
+		i = j
+	}
+	values := make([]reflect.Value, len(keys))
+	for i, key := range keys {
+		values[i] = key.value
+	}
+	return values, nil
+}
+
+// scratchString builds v with a builder sharing the configuration but not
+// the helper variables, and returns the printed expression. Pointers are
+// printed with the pointed values, so the string depends only on the value.
+func (b *builder) scratchString(v reflect.Value) (string, error) {
+	s := *b
+	s.scratch, s.vars, s.varNames, s.comments = true, nil, nil, nil
+	e, err := s.buildExpr(v)
+	if err != nil {
+		return "", err
 	}
+	return b.sprint(e), nil
+}
+
+// buildElem builds an element of a composite literal, eliding the type of
+// the element literal.
+func (b *builder) buildElem(v reflect.Value) (ast.Expr, error) {
+	b.elide = true
+	e, err := b.buildExpr(v)
+	if err != nil {
+		return nil, err
+	}
+	return dropLitType(e), nil
+}
+
+// buildLitType builds the type of a composite literal, or returns nil if the
+// type is elided.
+func (b *builder) buildLitType(t reflect.Type, elide bool) (ast.Expr, error) {
+	if elide {
+		return nil, nil
+	}
+	return b.buildType(t)
+}
+
+// sprint prints the expression reusing the scratch buffer.
+func (b *builder) sprint(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return e.Value
+	case *ast.Ident:
+		return e.Name
+	}
+	if b.fset == nil {
+		b.fset = token.NewFileSet()
+	}
+	b.buf.Reset()
+	printer.Fprint(&b.buf, b.fset, e)
+	return b.buf.String()
 }
 
 func dropLitType(v ast.Expr) ast.Expr {
@@ -229,35 +656,109 @@ func dropLitType(v ast.Expr) ast.Expr {
 	return v
 }
 
-type unexpectedTypeError struct{ t reflect.Type }
+// isDefinedLit reports whether the expression is the untyped literal of the
+// value of the defined type, like type y int, which needs the conversion to
+// keep the type in the interface.
+func isDefinedLit(t reflect.Type, e ast.Expr) bool {
+	if t.PkgPath() == "" {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		e, ok := e.(*ast.Ident)
+		return ok && (e.Name == "true" || e.Name == "false")
+	case reflect.Int, reflect.Float64, reflect.String:
+		_, ok := e.(*ast.BasicLit)
+		return ok
+	default:
+		return false
+	}
+}
 
-func (err *unexpectedTypeError) Error() string {
-	return fmt.Sprintf("unexpected type: %s", err.t.Kind())
+// buildBuiltin builds the value by the builtin rules of the types of the
+// standard library, and reports whether the type has the rule.
+func (b *builder) buildBuiltin(v reflect.Value) (ast.Expr, bool, error) {
+	if r, ok := builtinRules[v.Type()]; ok {
+		e, err := r(b, v)
+		return e, true, err
+	}
+	if b.textHelper && isTextType(v.Type()) {
+		e, err := b.buildTextHelperCall(v)
+		return e, true, err
+	}
+	if b.syncMap != SyncMapKeep && isSyncMap(v.Type()) {
+		e, err := b.buildSyncMap(v)
+		return e, true, err
+	}
+	if isAtomic(v.Type()) {
+		e, err := b.buildAtomic(v)
+		return e, true, err
+	}
+	if b.errorValues {
+		return b.buildErrorValue(v)
+	}
+	return nil, false, nil
+}
+
+// buildNonFinite builds math.NaN() or math.Inf(sign) for the float values
+// having no literal representation, or returns nil for the finite values.
+func (b *builder) buildNonFinite(f float64) ast.Expr {
+	switch {
+	case math.IsNaN(f):
+		return &ast.CallExpr{Fun: b.selector("math", "NaN")}
+	case math.IsInf(f, 0):
+		sign := int64(1)
+		if f < 0 {
+			sign = -1
+		}
+		return &ast.CallExpr{Fun: b.selector("math", "Inf"), Args: []ast.Expr{intLit(sign)}}
+	default:
+		return nil
+	}
 }
 
-func callExpr(kind token.Token, name, value string) *ast.CallExpr {
+// buildNonFiniteComplex builds the complex number having a non-finite part
+// by the builtin complex function, or returns nil if both parts are finite.
+func (b *builder) buildNonFiniteComplex(c complex128, bitSize int) ast.Expr {
+	re, im := b.buildNonFinite(real(c)), b.buildNonFinite(imag(c))
+	if re == nil && im == nil {
+		return nil
+	}
+	if re == nil {
+		re = &ast.BasicLit{Kind: token.FLOAT, Value: strconv.FormatFloat(real(c), 'g', -1, bitSize)}
+	}
+	if im == nil {
+		im = &ast.BasicLit{Kind: token.FLOAT, Value: strconv.FormatFloat(imag(c), 'g', -1, bitSize)}
+	}
+	return &ast.CallExpr{Fun: &ast.Ident{Name: "complex"}, Args: []ast.Expr{re, im}}
+}
+
+func callExpr(kind token.Token, fun ast.Expr, value string) *ast.CallExpr {
 	return &ast.CallExpr{
-		Fun: &ast.Ident{Name: name},
+		Fun: fun,
 		Args: []ast.Expr{
 			&ast.BasicLit{Kind: kind, Value: value},
 		},
 	}
 }
 
-func (b *builder) getVarName(v reflect.Value, t, e ast.Expr) string {
-	for _, bv := range b.vars {
-		if reflect.DeepEqual(t, bv.typ) && reflect.DeepEqual(e, bv.expr) {
-			return bv.name
+func (b *builder) getVarIdent(v reflect.Value, t, e ast.Expr) *ast.Ident {
+	for i := range b.vars {
+		if bv := &b.vars[i]; reflect.DeepEqual(t, bv.typ) && reflect.DeepEqual(e, bv.expr) {
+			bv.refs++
+			return bv.ident
 		}
 	}
-	var sb strings.Builder
-	printer.Fprint(&sb, token.NewFileSet(), e)
+	str := b.sprint(e)
+	if b.namePrefix != "" { // derive the same name regardless of the prefix
+		str = strings.ReplaceAll(str, b.namePrefix, "")
+	}
 	base := strings.Map(func(r rune) rune {
 		if '0' <= r && r <= '9' || 'A' <= r && r <= 'Z' || 'a' <= r && r <= 'z' {
 			return r
 		}
 		return -1
-	}, sb.String())
+	}, str)
 	typ := v.Type().Name()
 	if typ == "" {
 		var b bool
@@ -267,53 +768,165 @@ func (b *builder) getVarName(v reflect.Value, t, e ast.Expr) string {
 			}
 			b = true
 			return -1
-		}, sb.String())
+		}, str)
 	}
 	if len(typ) > 1 {
 		base = strings.ReplaceAll(base, typ, typ[:1])
 	}
-	if len(base) == 0 || '0' <= base[0] && base[0] <= '9' {
-		base = "x" + base
+	// Drop the digits so that the numeric suffixes are unambiguous.
+	base = strings.Map(func(r rune) rune {
+		if '0' <= r && r <= '9' {
+			return -1
+		}
+		return r
+	}, base)
+	if base == "" {
+		base = "x"
 	}
 	if len(base) > 3 {
 		base = base[:3]
 	}
-	i := min(len(base), 1)
-	name := base[:i]
-	for {
-		if !slices.ContainsFunc(b.vars, func(bv builderVar) bool {
-			return bv.name == name
-		}) {
-			break
+	var exact bool
+	if b.nameGen != nil {
+		if name := b.nameGen.VarName(v); token.IsIdentifier(name) {
+			base, exact = name, true
 		}
-		if i++; i <= len(base) {
+	}
+	short := 1
+	if exact {
+		short = len(base)
+	}
+	ident := &ast.Ident{Name: b.newVarName(base, short, nil)}
+	bv := builderVar{ident: ident, base: base, exact: exact, typ: t, expr: e, varptr: b.refersVars(e), refs: 1}
+	b.vars = append(b.vars, bv)
+	return ident
+}
+
+// newVarName returns the first available name derived from base, avoiding
+// the reserved identifiers, the used identifiers, and the names of the
+// helper variables registered so far. The candidates are the prefixes of
+// base not shorter than short, followed by base with numeric suffixes. The
+// names are prefixed by the prefix configured by WithNamePrefix.
+func (b *builder) newVarName(base string, short int, used map[string]bool) string {
+	for i := max(short, 1); ; i++ {
+		name := base
+		if i < len(base) {
 			name = base[:i]
-		} else {
+		} else if i > len(base) {
 			name = base + strconv.Itoa(i-len(base))
 		}
+		name = b.namePrefix + name
+		if !isReservedName(name) && !used[name] && !b.varNames[name] {
+			if b.varNames == nil {
+				b.varNames = make(map[string]bool)
+			}
+			b.varNames[name] = true
+			return name
+		}
 	}
-	bv := builderVar{name: name, typ: t, expr: e, varptr: isIdentPtrExpr(e)}
-	b.vars = append(b.vars, bv)
-	return name
+}
+
+// resolveVarNames renames the helper variables so that they do not collide
+// with the names and any other identifier in the trees, including type names.
+func (b *builder) resolveVarNames(names []string, nodes ...ast.Node) {
+	idents := make(map[*ast.Ident]bool, len(b.vars))
+	for _, bv := range b.vars {
+		idents[bv.ident] = true
+	}
+	used := make(map[string]bool)
+	for _, name := range names {
+		used[name] = true
+	}
+	collect := func(n ast.Node) bool {
+		if n, ok := n.(*ast.Ident); ok && !idents[n] {
+			used[n.Name] = true
+		}
+		return true
+	}
+	for _, n := range nodes {
+		ast.Inspect(n, collect)
+	}
+	for _, bv := range b.vars {
+		ast.Inspect(bv.typ, collect)
+		ast.Inspect(bv.expr, collect)
+	}
+	clear(b.varNames)
+	for _, bv := range b.vars {
+		bv.ident.Name = b.newVarName(bv.base, b.shortName(bv), used)
+	}
+}
+
+// shortName returns the length of the shortest prefix of the base of the
+// helper variable distinct from the bases of the other variables, so that the
+// names are not confusingly similar. The variables of the same base, and of
+// the exact base are numbered instead.
+func (b *builder) shortName(bv builderVar) int {
+	if bv.exact {
+		return len(bv.base)
+	}
+	short := 1
+	for _, other := range b.vars {
+		if other.ident == bv.ident || other.exact {
+			continue
+		}
+		if other.base == bv.base {
+			return len(bv.base)
+		}
+		var i int
+		for i < len(bv.base) && i < len(other.base) && bv.base[i] == other.base[i] {
+			i++
+		}
+		short = max(short, min(i+1, len(bv.base)))
+	}
+	return short
+}
+
+func isReservedName(name string) bool {
+	if token.Lookup(name).IsKeyword() {
+		return true
+	}
+	switch name {
+	case "any", "bool", "byte", "comparable", "complex64", "complex128",
+		"error", "float32", "float64", "int", "int8", "int16", "int32",
+		"int64", "rune", "string", "uint", "uint8", "uint16", "uint32",
+		"uint64", "uintptr", "true", "false", "iota", "nil", "append",
+		"cap", "clear", "close", "complex", "copy", "delete", "imag", "len",
+		"make", "max", "min", "new", "panic", "print", "println", "real",
+		"recover":
+		return true
+	}
+	return false
 }
 
 func (b *builder) newPtrExpr(v reflect.Value, e ast.Expr) (ast.Expr, error) {
-	t, err := buildType(v.Type())
+	if b.scratch {
+		return &ast.UnaryExpr{Op: token.AND, X: e}, nil
+	}
+	t, err := b.buildType(v.Type())
 	if err != nil {
 		return nil, err
 	}
 	return &ast.UnaryExpr{
 		Op: token.AND,
-		X:  &ast.Ident{Name: b.getVarName(v, t, e)},
+		X:  b.getVarIdent(v, t, e),
 	}, nil
 }
 
-func isIdentPtrExpr(e ast.Expr) bool {
-	if e, ok := e.(*ast.UnaryExpr); ok {
-		if e.Op == token.AND {
-			_, ok := e.X.(*ast.Ident)
-			return ok
+// refersVars reports whether the expression refers to the helper variables,
+// like the pointer of a helper variable and the call of a helper function.
+// The expression is typed, and should be assigned after the variables.
+func (b *builder) refersVars(e ast.Expr) bool {
+	var found bool
+	ast.Inspect(e, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && !found {
+			for _, bv := range b.vars {
+				if bv.ident == id {
+					found = true
+					break
+				}
+			}
 		}
-	}
-	return false
+		return !found
+	})
+	return found
 }
@@ -1,9 +1,12 @@
 package astgen_test
 
 import (
+	"fmt"
+	"go/ast"
 	"go/parser"
 	"go/printer"
 	"go/token"
+	"go/types"
 	"math"
 	"strings"
 	"testing"
@@ -96,6 +99,41 @@ var testCases = []struct {
 		src:      3.00,
 		expected: `3.0`,
 	},
+	{
+		name:     "float64 exponent",
+		src:      1e6,
+		expected: `1e+06`,
+	},
+	{
+		name:     "float64 small exponent",
+		src:      1e-7,
+		expected: `1e-07`,
+	},
+	{
+		name:     "float64 large exponent",
+		src:      1e21,
+		expected: `1e+21`,
+	},
+	{
+		name:     "float64 nan",
+		src:      math.NaN(),
+		expected: `math.NaN()`,
+	},
+	{
+		name:     "float64 positive infinity",
+		src:      math.Inf(1),
+		expected: `math.Inf(1)`,
+	},
+	{
+		name:     "float64 negative infinity",
+		src:      math.Inf(-1),
+		expected: `math.Inf(-1)`,
+	},
+	{
+		name:     "float32 nan",
+		src:      float32(math.NaN()),
+		expected: `float32(math.NaN())`,
+	},
 	{
 		name:     "complex64",
 		src:      complex64(1 - 2i),
@@ -106,6 +144,21 @@ var testCases = []struct {
 		src:      -3.14156 + 2.71828i,
 		expected: `complex128((-3.14156+2.71828i))`,
 	},
+	{
+		name:     "complex64 inf",
+		src:      complex64(complex(math.Inf(1), 0)),
+		expected: `complex64(complex(math.Inf(1), 0))`,
+	},
+	{
+		name:     "complex128 nan",
+		src:      complex(math.NaN(), 1.5),
+		expected: `complex(math.NaN(), 1.5)`,
+	},
+	{
+		name:     "complex128 inf and nan",
+		src:      complex(math.Inf(-1), math.NaN()),
+		expected: `complex(math.Inf(-1), math.NaN())`,
+	},
 	{
 		name:     "string",
 		src:      "Hello, world!",
@@ -239,18 +292,17 @@ var testCases = []struct {
 })(42)`,
 	},
 	{
-		// This case should be fixed to avoid identifier conflict.
 		name: "pointer of literal in struct",
 		src:  &x{ptr: (func(i int) *int { return &i })(42)},
-		expected: `(func(x int) *x {
-	return &x{ptr: &x}
+		expected: `(func(x1 int) *x {
+	return &x{ptr: &x1}
 })(42)`,
 	},
 	{
 		name: "array of struct",
 		src:  [1]*x{{ptr: (func(i int) *int { return &i })(42)}},
-		expected: `(func(x int) [1]*x {
-	return [1]*x{{ptr: &x}}
+		expected: `(func(x1 int) [1]*x {
+	return [1]*x{{ptr: &x1}}
 })(42)`,
 	},
 	{
@@ -273,7 +325,7 @@ var testCases = []struct {
 			b: (func(i y) *y { return &i })(2),
 			c: (func(i y) *y { return &i })(1),
 		},
-		expected: `(func(f, b, ba z, x, x2 y) struct {
+		expected: `(func(f, bar, bar1 z, x1, x2 y) struct {
 	x	x
 	y	y
 	z, w, u	*z
@@ -284,7 +336,7 @@ var testCases = []struct {
 		y	y
 		z, w, u	*z
 		a, b, c	*y
-	}{y: 1, z: &f, w: &b, u: &ba, a: &x, b: &x2, c: &x}
+	}{y: 1, z: &f, w: &bar, u: &bar1, a: &x1, b: &x2, c: &x1}
 })("foo", "bar", "barr", 1, 2)`,
 	},
 	{
@@ -296,8 +348,8 @@ var testCases = []struct {
 			5: (func(s string) *string { return &s })("fo"),
 			7: (func(s string) *string { return &s })("ba"),
 		},
-		expected: `(func(f, b, fo, ba string) map[int]*string {
-	return map[int]*string{2: &f, 3: &f, 4: &b, 5: &fo, 7: &ba}
+		expected: `(func(foo, bar, fo, ba string) map[int]*string {
+	return map[int]*string{2: &foo, 3: &foo, 4: &bar, 5: &fo, 7: &ba}
 })("foo", "bar", "fo", "ba")`,
 	},
 	{
@@ -332,7 +384,7 @@ var testCases = []struct {
 			"o": (func(x any) *any { return &x })(nil),
 			"p": (func(x any) *any { return &x })(struct{}{}),
 		},
-		expected: `(func(x int, i int8, i1 int16, i10 int32, i101 int64, u uint, u1 uint8, u10 uint16, u101 uint32, u102 uint64, f float32, x1 float64, c complex64, c1 complex128, in, is interface {
+		expected: `(func(x int, i int8, i1 int16, i2 int32, i3 int64, u uint, u1 uint8, u2 uint16, u3 uint32, u4 uint64, f float32, x1 float64, ci complex64, ci1 complex128, in, is interface {
 }) map[string]interface {
 } {
 	return map[string]interface {
@@ -340,17 +392,17 @@ var testCases = []struct {
 	}(&x), "b": interface {
 	}(&i), "c": interface {
 	}(&i1), "d": interface {
-	}(&i10), "e": interface {
-	}(&i101), "f": interface {
+	}(&i2), "e": interface {
+	}(&i3), "f": interface {
 	}(&u), "g": interface {
 	}(&u1), "h": interface {
-	}(&u10), "i": interface {
-	}(&u101), "j": interface {
-	}(&u102), "k": interface {
+	}(&u2), "i": interface {
+	}(&u3), "j": interface {
+	}(&u4), "k": interface {
 	}(&f), "l": interface {
 	}(&x1), "m": interface {
-	}(&c), "n": interface {
-	}(&c1), "o": interface {
+	}(&ci), "n": interface {
+	}(&ci1), "o": interface {
 	}(&in), "p": interface {
 	}(&is)}
 })(10, int8(10), int16(10), int32(10), int64(10), uint(10), uint8(10), uint16(10), uint32(10), uint64(10), float32(10), 10.0, complex64((10+0i)), complex128((10+0i)), interface {
@@ -365,21 +417,51 @@ var testCases = []struct {
 			"b": (func(x bool) **bool { y := &x; return &y })(false),
 			"c": (func(x string) ***string { y := &x; z := &y; return &z })(""),
 		},
-		expected: `(func(s *struct {
-}, f bool, x string) map[string]interface {
+		expected: `(func(st *struct {
+}, fa bool, x string) map[string]interface {
 } {
-	s1 := &s
-	f1 := &f
+	s := &st
+	f := &fa
 	x1 := &x
-	x11 := &x1
+	x2 := &x1
 	return map[string]interface {
 	}{"a": interface {
-	}(&s1), "b": interface {
-	}(&f1), "c": interface {
-	}(&x11)}
+	}(&s), "b": interface {
+	}(&f), "c": interface {
+	}(&x2)}
 })(&struct {
 }{}, false, "")`,
 	},
+	{
+		name: "nil pointers",
+		src:  []any{[]*int{nil}, (*string)(nil), (func(p *int) **int { return &p })(nil)},
+		expected: `(func(n *int) []interface {
+} {
+	return []interface {
+	}{interface {
+	}([]*int{nil}), interface {
+	}((*string)(nil)), interface {
+	}(&n)}
+})(nil)`,
+	},
+	{
+		name: "defined types",
+		src:  []any{y(1), z("foo"), w(0.5), []y{2}, map[z]any{"bar": z("baz")}},
+		expected: `[]interface {
+}{interface {
+}(y(1)), interface {
+}(z("foo")), interface {
+}(w(0.5)), interface {
+}([]y{2}), interface {
+}(map[z]interface {
+}{"bar": interface {
+}(z("baz"))})}`,
+	},
+	{
+		name:     "defined composite types",
+		src:      map[z]v{"foo": {{1}, {2, 3}}, "bar": nil},
+		expected: `map[z]v{"bar": {}, "foo": {{1, 0}, {2, 3}}}`,
+	},
 }
 
 type x struct {
@@ -390,6 +472,9 @@ type x struct {
 type (
 	y int
 	z string
+	w float32
+	u [2]y
+	v []u
 )
 
 func TestBuild(t *testing.T) {
@@ -411,3 +496,150 @@ func TestBuild(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildDeterministic(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	src := map[*string]*string{}
+	for _, s := range []string{"foo", "bar", "baz", "qux", "quux", "foo", "bar"} {
+		src[ptr(s)] = ptr(s + "!")
+	}
+	var expected string
+	for i := 0; i < 20; i++ {
+		got, err := astgen.Build(src)
+		if err != nil {
+			t.Fatalf("should not return error: %s", err)
+		}
+		var sb strings.Builder
+		printer.Fprint(&sb, token.NewFileSet(), got)
+		if i == 0 {
+			expected = sb.String()
+		} else if sb.String() != expected {
+			t.Fatalf("expected: %s\ngot: %s", expected, sb.String())
+		}
+	}
+}
+
+func TestBuildManyPointers(t *testing.T) {
+	var src []any
+	for i := 0; i < 300; i++ {
+		n, str := i, fmt.Sprintf("s%d", i)
+		src = append(src, &n, &str, (func(f float64) *float64 { return &f })(float64(i)/3))
+	}
+	got, err := astgen.Build(src)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	sb.WriteString("package p\n\nvar _ = ")
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", sb.String(), 0)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if _, err = new(types.Config).Check("p", fset, []*ast.File{f}, nil); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	names := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n, ok := n.(*ast.FuncType); ok {
+			for _, field := range n.Params.List {
+				for _, name := range field.Names {
+					if names[name.Name] {
+						t.Errorf("duplicate parameter name: %s", name.Name)
+					}
+					names[name.Name] = true
+				}
+			}
+		}
+		return true
+	})
+	if len(names) != 900 {
+		t.Errorf("expected 900 parameters but got %d", len(names))
+	}
+}
+
+func TestBuildDeepNesting(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	type list struct {
+		next  *list
+		value int
+	}
+	var l *list
+	for i := 0; i < 1000000; i++ {
+		l = &list{l, i}
+	}
+	if _, err := astgen.Build(l); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+}
+
+func TestMustBuild(t *testing.T) {
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), astgen.MustBuild([]int{1, 2}))
+	if expected := "[]int{1, 2}"; sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+	defer func() {
+		if expected, got := "astgen: unexpected type: chan", recover(); got != expected {
+			t.Errorf("expected: %s\ngot: %v", expected, got)
+		}
+	}()
+	astgen.MustBuild(make(chan int))
+}
+
+func BenchmarkBuildSlice(b *testing.B) {
+	src := make([]any, 10000)
+	for i := range src {
+		switch i % 4 {
+		case 0:
+			src[i] = i
+		case 1:
+			src[i] = float64(i) / 7
+		case 2:
+			src[i] = uint32(i)
+		default:
+			src[i] = complex(float64(i), -1)
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := astgen.Build(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildMap(b *testing.B) {
+	src := make(map[string][]int, 10000)
+	for i := 0; i < 10000; i++ {
+		src[fmt.Sprintf("key%d", i)] = []int{i, i * 2, i * 3}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := astgen.Build(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildStruct(b *testing.B) {
+	type item struct {
+		ID    int64
+		Name  string
+		Score float64
+		Tags  []string
+	}
+	src := make([]item, 10000)
+	for i := range src {
+		src[i] = item{int64(i), fmt.Sprintf("item%d", i), float64(i) / 3, []string{"a", "b"}}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := astgen.Build(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
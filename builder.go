@@ -0,0 +1,103 @@
+package astgen
+
+import (
+	"go/ast"
+	"reflect"
+	"sort"
+)
+
+// Builder builds the values with the shared configuration. The builder
+// reuses the allocations and the cached zero values across the builds, which
+// is efficient for building many values. A Builder is not safe for
+// concurrent use.
+type Builder struct {
+	b    *builder
+	last ast.Node
+}
+
+// NewBuilder creates a new Builder configured by the options.
+func NewBuilder(opts ...Option) *Builder {
+	return &Builder{b: newBuilder(opts)}
+}
+
+// Build builds the ast of x like Build.
+func (b *Builder) Build(x any) (ast.Node, error) {
+	b.b.reset()
+	n, err := b.b.buildNode(reflect.ValueOf(x))
+	b.last = n
+	return n, err
+}
+
+// Stats is the statistics of a build, which is useful to log the complexity
+// of the generated code.
+type Stats struct {
+	Nodes      int // number of the nodes of the result
+	MaxDepth   int // maximum depth of the nested values
+	PtrVars    int // number of the helper variables of the pointers
+	SharedVars int // number of the helper variables referred more than once
+}
+
+// Stats returns the statistics of the last build.
+func (b *Builder) Stats() Stats {
+	s := Stats{MaxDepth: b.b.deepest}
+	if b.last != nil {
+		ast.Inspect(b.last, func(n ast.Node) bool {
+			if n != nil {
+				s.Nodes++
+			}
+			return true
+		})
+	}
+	for _, bv := range b.b.vars {
+		if bv.helper {
+			continue
+		}
+		s.PtrVars++
+		if bv.refs > 1 {
+			s.SharedVars++
+		}
+	}
+	return s
+}
+
+// Imports returns the sorted paths of the packages which the result of the
+// last build refers to.
+func (b *Builder) Imports() []string {
+	paths := make([]string, 0, len(b.b.imports))
+	for path := range b.b.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Register registers the function building the expressions of the values
+// of the type, which overrides the default rule and the ASTMarshaler of the
+// type. This is useful for the types of other packages like decimal types.
+func (b *Builder) Register(t reflect.Type, fn func(reflect.Value) (ast.Expr, error)) {
+	if b.b.rules == nil {
+		b.b.rules = make(map[reflect.Type]func(reflect.Value) (ast.Expr, error))
+	}
+	b.b.rules[t] = fn
+	b.b.zeros = nil
+}
+
+// Reset discards the cached values retained across the builds, keeping the
+// configuration.
+func (b *Builder) Reset() {
+	b.b.reset()
+	b.b.zeros, b.b.fset, b.last = nil, nil, nil
+	b.b.buf.Reset()
+}
+
+// reset clears the state of the previous build, reusing the allocations.
+func (b *builder) reset() {
+	b.steps, b.nodes, b.scratch, b.depth, b.deepest, b.budgetUsed, b.elide = 0, 0, false, 0, 0, 0, false
+	b.comments = nil
+	clear(b.imports)
+	clear(b.rowLits)
+	clear(b.ptrCalls)
+	clear(b.vars)
+	b.vars = b.vars[:0]
+	clear(b.varNames)
+}
@@ -0,0 +1,146 @@
+package astgen_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuilder(t *testing.T) {
+	type T struct {
+		X *int
+		Y [2]struct{ Z int }
+	}
+	i, j := 1, 2
+	b := astgen.NewBuilder(astgen.WithNormalize())
+	for _, tc := range []struct {
+		src      any
+		expected string
+	}{
+		{
+			src: T{X: &i},
+			expected: `func(x int) T {
+	return T{X: &x}
+}(1)`,
+		},
+		{
+			src: T{X: &j},
+			expected: `func(x int) T {
+	return T{X: &x}
+}(2)`,
+		},
+		{
+			src:      []T{{}, {}},
+			expected: `[]T{{}, {}}`,
+		},
+	} {
+		for _, reset := range []bool{false, true} {
+			if reset {
+				b.Reset()
+			}
+			got, err := b.Build(tc.src)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		}
+	}
+}
+
+func BenchmarkBuilder(b *testing.B) {
+	type T struct {
+		X *int
+		Y [16]struct{ Z int }
+	}
+	xs := make([]T, 100)
+	for i := range xs {
+		xs[i].X = new(int)
+	}
+	builder := astgen.NewBuilder()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := builder.Build(xs[i%len(xs)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBuilderRegister(t *testing.T) {
+	type T struct {
+		D time.Duration
+		V version
+		X []time.Duration
+	}
+	b := astgen.NewBuilder()
+	b.Register(reflect.TypeOf(time.Duration(0)), func(v reflect.Value) (ast.Expr, error) {
+		return parser.ParseExpr(fmt.Sprintf("%d * time.Millisecond", v.Interface().(time.Duration).Milliseconds()))
+	})
+	b.Register(reflect.TypeOf(version{}), func(v reflect.Value) (ast.Expr, error) {
+		return &ast.Ident{Name: "v" + strconv.Itoa(int(v.Field(0).Int()))}, nil
+	})
+	got, err := b.Build(T{D: time.Second, V: version{1, 0}, X: []time.Duration{0, time.Minute}})
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `T{D: 1000 * time.Millisecond, V: v1, X: []time.Duration{0 * time.Millisecond, 60000 * time.Millisecond}}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
+
+func TestBuilderStats(t *testing.T) {
+	type T struct {
+		Name  string
+		Refs  []*int
+		Child *T
+	}
+	x, y := 1, 2
+	b := astgen.NewBuilder()
+	if _, err := b.Build(T{Name: "foo", Refs: []*int{&x, &y, &x}, Child: &T{Name: "bar"}}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := astgen.Stats{Nodes: 41, MaxDepth: 4, PtrVars: 2, SharedVars: 1}
+	if got := b.Stats(); got != expected {
+		t.Errorf("expected: %+v\ngot: %+v", expected, got)
+	}
+	if _, err := b.Build([]int{1}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected = astgen.Stats{Nodes: 4, MaxDepth: 2}
+	if got := b.Stats(); got != expected {
+		t.Errorf("expected: %+v\ngot: %+v", expected, got)
+	}
+}
+
+func TestBuilderImports(t *testing.T) {
+	b := astgen.NewBuilder()
+	if _, err := b.Build([]any{time.Second, json.Number("1"), time.Minute}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := []string{"encoding/json", "time"}
+	if got := b.Imports(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected: %v\ngot: %v", expected, got)
+	}
+	if _, err := b.Build([]int{1}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if got := b.Imports(); len(got) != 0 {
+		t.Errorf("expected no imports\ngot: %v", got)
+	}
+}
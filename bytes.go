@@ -0,0 +1,111 @@
+package astgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WithBytesAsString makes Build emit the byte slices of the UTF-8 texts as
+// the conversions from the string literals like []byte("Hello"), instead of
+// the literals of the elements. The binary data are built as the elements.
+func WithBytesAsString() Option {
+	return func(b *builder) {
+		b.bytesString = true
+	}
+}
+
+// WithBytesAsHex makes Build emit the byte slices and arrays as the literals
+// of the hexadecimal bytes like []byte{0x1f, 0x8b}, printed by Source in the
+// lines of perLine bytes if positive. The texts are built as the strings if
+// configured by WithBytesAsString.
+func WithBytesAsHex(perLine int) Option {
+	return func(b *builder) {
+		b.bytesHex = true
+		b.hexPerLine = perLine
+	}
+}
+
+var byteType = reflect.TypeOf(byte(0))
+
+// isText reports whether the data is a non-empty valid UTF-8 text of the
+// printable characters and the white spaces.
+func isText(data []byte) bool {
+	if len(data) == 0 || !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && r != '\t' && r != '\n' && r != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// buildBytesString builds the byte slice as the conversion from the string.
+func (b *builder) buildBytesString(v reflect.Value) (ast.Expr, error) {
+	var t ast.Expr = &ast.ArrayType{Elt: &ast.Ident{Name: "byte"}}
+	if v.Type().Name() != "" {
+		t = b.typeName(v.Type())
+	}
+	return &ast.CallExpr{Fun: t, Args: []ast.Expr{quoteString(string(v.Bytes()))}}, nil
+}
+
+// buildBytesLit builds a byte slice as a conversion from the string literal.
+func buildBytesLit(data []byte) ast.Expr {
+	return &ast.CallExpr{
+		Fun:  &ast.ArrayType{Elt: &ast.Ident{Name: "byte"}},
+		Args: []ast.Expr{stringLit(strconv.Quote(string(data)))},
+	}
+}
+
+func stringLit(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: s}
+}
+
+// buildBytesHex builds the byte slice or array as the composite literal of
+// the hexadecimal bytes, and records the literal to wrap the lines.
+func (b *builder) buildBytesHex(v reflect.Value, elide bool) (ast.Expr, error) {
+	if err := b.checkNodes(v.Len()); err != nil {
+		return nil, err
+	}
+	exprs := make([]ast.Expr, v.Len())
+	for i := range exprs {
+		exprs[i] = &ast.BasicLit{Kind: token.INT, Value: fmt.Sprintf("0x%02x", v.Index(i).Uint())}
+	}
+	var t ast.Expr
+	switch {
+	case elide:
+	case v.Type().Name() != "":
+		t = b.typeName(v.Type())
+	case v.Kind() == reflect.Array:
+		t = &ast.ArrayType{Len: intLit(int64(v.Len())), Elt: &ast.Ident{Name: "byte"}}
+	default:
+		t = &ast.ArrayType{Elt: &ast.Ident{Name: "byte"}}
+	}
+	lit := &ast.CompositeLit{Type: t, Elts: exprs}
+	if b.hexPerLine > 0 && len(exprs) > b.hexPerLine {
+		if b.rowLits == nil {
+			b.rowLits = make(map[*ast.CompositeLit]int)
+		}
+		b.rowLits[lit] = b.hexPerLine
+	}
+	return lit, nil
+}
+
+// quoteString builds the string literal, quoted as a raw string if it
+// contains the double quotes and no other characters to be escaped.
+func quoteString(s string) *ast.BasicLit {
+	if strings.ContainsRune(s, '"') && !strings.ContainsRune(s, '`') {
+		t := strings.ReplaceAll(s, `"`, "")
+		if len(strconv.Quote(t)) == len(t)+2 { // check no escape characters
+			return &ast.BasicLit{Kind: token.STRING, Value: "`" + s + "`"}
+		}
+	}
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(s)}
+}
@@ -0,0 +1,75 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithBytesAsString(t *testing.T) {
+	type blob []byte
+	type T struct {
+		Text   []byte
+		Quoted []byte
+		Binary []byte
+		Named  blob
+		Array  [2]byte
+	}
+	src := T{
+		Text:   []byte("Hello, 世界\n"),
+		Quoted: []byte(`{"x": 1}`),
+		Binary: []byte{0x1f, 0x8b, 0x00},
+		Named:  blob("foo"),
+		Array:  [2]byte{'a', 'b'},
+	}
+	got, err := astgen.Build(src, astgen.WithBytesAsString())
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := "T{Text: []byte(\"Hello, 世界\\n\"), Quoted: []byte(`{\"x\": 1}`), " +
+		"Binary: []uint8{uint8(31), uint8(139), uint8(0)}, Named: blob(\"foo\"), " +
+		"Array: [2]uint8{uint8(97), uint8(98)}}"
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+	if err := astgen.Check(src, astgen.WithBytesAsString()); err != nil {
+		t.Errorf("should not return error: %s", err)
+	}
+}
+
+func TestBuildWithBytesAsHex(t *testing.T) {
+	type digest [4]byte
+	type T struct {
+		Magic  []byte
+		Table  []byte
+		Sum    digest
+		Pair   [2]byte
+		Blocks [][]byte
+		Text   []byte
+	}
+	src := T{
+		Magic:  []byte{0x1f, 0x8b},
+		Table:  []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		Sum:    digest{0xde, 0xad, 0xbe, 0xef},
+		Pair:   [2]byte{0xff},
+		Blocks: [][]byte{{0xca, 0xfe}},
+		Text:   []byte("foo"),
+	}
+	got, err := astgen.Source(src, astgen.WithBytesAsHex(4), astgen.WithBytesAsString())
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `T{Magic: []byte{0x1f, 0x8b}, Table: []byte{
+	0x00, 0x01, 0x02, 0x03,
+	0x04, 0x05, 0x06, 0x07,
+	0x08, 0x09,
+}, Sum: digest{0xde, 0xad, 0xbe, 0xef}, Pair: [2]byte{0xff, 0x00}, Blocks: [][]uint8{{0xca, 0xfe}}, Text: []byte("foo")}`
+	if string(got) != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, got)
+	}
+}
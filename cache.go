@@ -0,0 +1,245 @@
+package astgen
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/binary"
+	"go/ast"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Cache memoizes the results of Build keyed by the fingerprints of the
+// values, so that the builds of identical values are served from the cache.
+// It is safe for concurrent use. A cache should be shared only among the
+// builds with the same options.
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	nodes map[fingerprint]*list.Element
+	lru   *list.List
+}
+
+type fingerprint [16]byte
+
+type cacheEntry struct {
+	key  fingerprint
+	node ast.Node
+	meta cacheMeta
+}
+
+// cacheMeta is the state of the builder after the build, which is replayed
+// on the cache hits, so that the imports and the statistics are reported.
+type cacheMeta struct {
+	imports []string
+	deepest int
+	vars    []builderVar
+	rowLits map[int]int // by the indices of the composite literals in the node
+}
+
+// NewCache creates a cache holding up to size results. If size is not
+// positive, the cache grows without bound.
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:  size,
+		nodes: make(map[fingerprint]*list.Element),
+		lru:   list.New(),
+	}
+}
+
+// WithCache makes Build look up and store the results in the cache.
+func WithCache(c *Cache) Option {
+	return func(b *builder) {
+		b.cache = c
+	}
+}
+
+func (c *Cache) get(key fingerprint) (ast.Node, cacheMeta, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.nodes[key]
+	if !ok {
+		return nil, cacheMeta{}, false
+	}
+	c.lru.MoveToFront(e)
+	entry := e.Value.(*cacheEntry)
+	return cloneNode(entry.node), entry.meta, true
+}
+
+func (c *Cache) put(key fingerprint, n ast.Node, meta cacheMeta) {
+	n = cloneNode(n)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.nodes[key]; ok {
+		entry := e.Value.(*cacheEntry)
+		entry.node, entry.meta = n, meta
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.nodes[key] = c.lru.PushFront(&cacheEntry{key, n, meta})
+	if c.size > 0 && c.lru.Len() > c.size {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.nodes, e.Value.(*cacheEntry).key)
+	}
+}
+
+// cacheMeta returns the state of the builder after building n.
+func (b *builder) cacheMeta(n ast.Node) cacheMeta {
+	m := cacheMeta{deepest: b.deepest, vars: slices.Clone(b.vars)}
+	for path := range b.imports {
+		m.imports = append(m.imports, path)
+	}
+	if len(b.rowLits) > 0 {
+		m.rowLits = make(map[int]int)
+		forEachLit(n, func(i int, lit *ast.CompositeLit) {
+			if k, ok := b.rowLits[lit]; ok {
+				m.rowLits[i] = k
+			}
+		})
+	}
+	return m
+}
+
+// replayMeta restores the state of the builder after building n, which is
+// the clone of the cached node.
+func (b *builder) replayMeta(n ast.Node, m cacheMeta) {
+	for _, path := range m.imports {
+		b.addImport(path)
+	}
+	b.deepest = max(b.deepest, m.deepest)
+	b.vars = append(b.vars, m.vars...)
+	if len(m.rowLits) > 0 {
+		if b.rowLits == nil {
+			b.rowLits = make(map[*ast.CompositeLit]int)
+		}
+		forEachLit(n, func(i int, lit *ast.CompositeLit) {
+			if k, ok := m.rowLits[i]; ok {
+				b.rowLits[lit] = k
+			}
+		})
+	}
+}
+
+// forEachLit calls f with the composite literals in n and their indices in
+// the order of ast.Inspect, which are the same in the clones of the node.
+func forEachLit(n ast.Node, f func(int, *ast.CompositeLit)) {
+	var i int
+	ast.Inspect(n, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.CompositeLit); ok {
+			f(i, lit)
+			i++
+		}
+		return true
+	})
+}
+
+// fingerprintOf computes the fingerprint of the value from its type and
+// contents. It reports false if the value contains an unsupported kind, or
+// refers to itself, which is not cacheable.
+func fingerprintOf(v reflect.Value) (fingerprint, bool) {
+	h := fnv.New128a()
+	if !writeFingerprint(h, v, make(map[visit]bool)) {
+		return fingerprint{}, false
+	}
+	var key fingerprint
+	h.Sum(key[:0])
+	return key, true
+}
+
+// visit is the reference on the path of writeFingerprint to detect cycles.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func writeFingerprint(h hash.Hash, v reflect.Value, visiting map[visit]bool) bool {
+	var buf [8]byte
+	writeUint := func(u uint64) {
+		binary.LittleEndian.PutUint64(buf[:], u)
+		h.Write(buf[:])
+	}
+	writeString := func(s string) {
+		writeUint(uint64(len(s)))
+		h.Write([]byte(s))
+	}
+	writeUint(uint64(v.Kind()))
+	if v.Kind() == reflect.Invalid {
+		return true
+	}
+	writeString(v.Type().PkgPath())
+	writeString(v.Type().String())
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if !v.IsNil() {
+			k := visit{v.Pointer(), v.Type()}
+			if visiting[k] {
+				return false
+			}
+			visiting[k] = true
+			defer delete(visiting, k)
+		}
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint(1)
+		} else {
+			writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		writeUint(math.Float64bits(real(v.Complex())))
+		writeUint(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		writeString(v.String())
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			writeUint(0)
+			return true
+		}
+		writeUint(1)
+		return writeFingerprint(h, v.Elem(), visiting)
+	case reflect.Array, reflect.Slice:
+		writeUint(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if !writeFingerprint(h, v.Index(i), visiting) {
+				return false
+			}
+		}
+	case reflect.Map:
+		// Sort the fingerprints of the entries to ignore the iteration order.
+		entries := make([][]byte, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			h := fnv.New128a()
+			if !writeFingerprint(h, iter.Key(), visiting) || !writeFingerprint(h, iter.Value(), visiting) {
+				return false
+			}
+			entries = append(entries, h.Sum(nil))
+		}
+		slices.SortFunc(entries, bytes.Compare)
+		writeUint(uint64(len(entries)))
+		for _, e := range entries {
+			h.Write(e)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !writeFingerprint(h, v.Field(i), visiting) {
+				return false
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package astgen_test
+
+import (
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithCache(t *testing.T) {
+	cache := astgen.NewCache(2)
+	build := func(x any) (ast.Node, string) {
+		t.Helper()
+		got, err := astgen.Build(x, astgen.WithCache(cache))
+		if err != nil {
+			t.Fatalf("should not return error: %s", err)
+		}
+		var sb strings.Builder
+		printer.Fprint(&sb, token.NewFileSet(), got)
+		return got, sb.String()
+	}
+	n1, s1 := build(map[string]*int{"a": new(int), "b": new(int)})
+	n2, s2 := build(map[string]*int{"b": new(int), "a": new(int)})
+	if s1 != s2 {
+		t.Errorf("expected: %s\ngot: %s", s1, s2)
+	}
+	if n1 == n2 {
+		t.Errorf("should return a cloned node")
+	}
+	n1.(*ast.CallExpr).Args = nil
+	if _, s := build(map[string]*int{"a": new(int), "b": new(int)}); s != s1 {
+		t.Errorf("expected: %s\ngot: %s", s1, s)
+	}
+	for _, tc := range []struct {
+		src      any
+		expected string
+	}{
+		{1, `1`},
+		{int64(1), `int64(1)`},
+		{[]any{1}, `[]interface {
+}{interface {
+}(1)}`},
+		{[]any{int8(1)}, `[]interface {
+}{interface {
+}(int8(1))}`},
+		{1, `1`},
+	} {
+		if _, s := build(tc.src); s != tc.expected {
+			t.Errorf("expected: %s\ngot: %s", tc.expected, s)
+		}
+	}
+}
+
+func TestBuilderWithCache(t *testing.T) {
+	type T struct {
+		Refs    []*int
+		Timeout time.Duration
+		Magic   []byte
+	}
+	x := 1
+	src := T{Refs: []*int{&x, &x}, Timeout: time.Second, Magic: []byte{1, 2, 3}}
+	cache := astgen.NewCache(0)
+	b := astgen.NewBuilder(astgen.WithCache(cache))
+	if _, err := b.Build(src); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	stats, imports := b.Stats(), b.Imports()
+	if _, err := b.Build(src); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if got := b.Stats(); got != stats {
+		t.Errorf("expected: %+v\ngot: %+v", stats, got)
+	}
+	if got := b.Imports(); !reflect.DeepEqual(got, imports) || len(got) != 1 {
+		t.Errorf("expected: %v\ngot: %v", imports, got)
+	}
+	opts := []astgen.Option{astgen.WithCache(astgen.NewCache(0)), astgen.WithBytesAsHex(2)}
+	expected, err := astgen.Source(src, opts...)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if got, err := astgen.Source(src, opts...); err != nil || string(got) != string(expected) {
+		t.Errorf("expected: %s\ngot: %s", expected, got)
+	}
+}
+
+type cacheNode struct {
+	Next *cacheNode
+}
+
+func TestBuildWithCacheCyclic(t *testing.T) {
+	n := &cacheNode{}
+	n.Next = n
+	m := map[string]any{}
+	m["m"] = m
+	for _, src := range []any{n, m} {
+		_, expected := astgen.Build(src, astgen.WithMaxDepth(5))
+		_, err := astgen.Build(src, astgen.WithMaxDepth(5), astgen.WithCache(astgen.NewCache(2)))
+		if err == nil || expected == nil || err.Error() != expected.Error() {
+			t.Errorf("expected: %v\ngot: %v", expected, err)
+		}
+	}
+}
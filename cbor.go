@@ -0,0 +1,303 @@
+package astgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+)
+
+// WithCBORTag makes BuildCBOR decode the CBOR tag by the function, which
+// receives the decoded tag content. The standard date/time tags (0 and 1) are
+// decoded to time.Time, and the bignum tags (2 and 3) to *big.Int by default.
+func WithCBORTag(tag uint64, decode func(any) (any, error)) Option {
+	return func(b *builder) {
+		if b.cborTags == nil {
+			b.cborTags = make(map[uint64]func(any) (any, error))
+		}
+		b.cborTags[tag] = decode
+	}
+}
+
+// BuildCBOR builds the CBOR encoded value, decoded by DecodeCBOR with the tags
+// configured by WithCBORTag.
+func BuildCBOR(data []byte, opts ...Option) (ast.Node, error) {
+	b := newBuilder(opts)
+	v, err := DecodeCBOR(data, b.cborTags)
+	if err != nil {
+		return nil, err
+	}
+	return b.buildNode(reflect.ValueOf(v))
+}
+
+// DecodeCBOR decodes the CBOR encoded value to a generic value. Integers are
+// decoded to int (or uint64, *big.Int if overflows), floats to float32 or
+// float64, text strings to string, byte strings to []byte, arrays to []any,
+// and maps to map[string]any if all the keys are strings, otherwise to
+// map[any]any. The tags are decoded by the functions in tags, and the unknown
+// tags are ignored.
+func DecodeCBOR(data []byte, tags map[uint64]func(any) (any, error)) (any, error) {
+	d := &cborDecoder{data: data, tags: tags}
+	v, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	if v == cborBreak {
+		return nil, fmt.Errorf("cbor: unexpected break at offset %d", d.offset-1)
+	}
+	if d.offset < len(d.data) {
+		return nil, fmt.Errorf("cbor: unexpected data after the value at offset %d", d.offset)
+	}
+	return v, nil
+}
+
+type cborDecoder struct {
+	data   []byte
+	offset int
+	tags   map[uint64]func(any) (any, error)
+}
+
+var errCBORUnexpectedEOF = errors.New("cbor: unexpected end of data")
+
+// cborBreak is the break stop code of the indefinite-length items.
+var cborBreak = &struct{}{}
+
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if uint64(len(d.data)-d.offset) < n {
+		return nil, errCBORUnexpectedEOF
+	}
+	bs := d.data[d.offset : d.offset+int(n)]
+	d.offset += int(n)
+	return bs, nil
+}
+
+// readHead reads the initial byte and the argument. The argument is -1 for
+// the indefinite length.
+func (d *cborDecoder) readHead() (byte, byte, uint64, bool, error) {
+	bs, err := d.read(1)
+	if err != nil {
+		return 0, 0, 0, false, err
+	}
+	major, info := bs[0]>>5, bs[0]&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), false, nil
+	case info <= 27:
+		bs, err := d.read(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		var u uint64
+		for _, b := range bs {
+			u = u<<8 | uint64(b)
+		}
+		return major, info, u, false, nil
+	case info == 31 && major >= 2 && major != 6:
+		return major, info, 0, true, nil
+	default:
+		return 0, 0, 0, false, fmt.Errorf("cbor: invalid additional information %d at offset %d", info, d.offset-1)
+	}
+}
+
+func (d *cborDecoder) decode() (any, error) {
+	major, info, arg, indefinite, err := d.readHead()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case 0:
+		if arg > math.MaxInt {
+			return arg, nil
+		}
+		return int(arg), nil
+	case 1:
+		if arg > math.MaxInt {
+			return new(big.Int).Not(new(big.Int).SetUint64(arg)), nil
+		}
+		return -1 - int(arg), nil
+	case 2, 3:
+		var bs []byte
+		if indefinite {
+			for {
+				v, err := d.decode()
+				if err != nil {
+					return nil, err
+				}
+				if v == cborBreak {
+					break
+				}
+				switch v := v.(type) {
+				case []byte:
+					if major == 3 {
+						return nil, errors.New("cbor: invalid chunk of text string")
+					}
+					bs = append(bs, v...)
+				case string:
+					if major == 2 {
+						return nil, errors.New("cbor: invalid chunk of byte string")
+					}
+					bs = append(bs, v...)
+				default:
+					return nil, errors.New("cbor: invalid chunk of indefinite-length string")
+				}
+			}
+		} else if bs, err = d.read(arg); err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(bs), nil
+		}
+		return append([]byte{}, bs...), nil
+	case 4:
+		var vs []any
+		for i := uint64(0); indefinite || i < arg; i++ {
+			v, err := d.decode()
+			if err != nil {
+				return nil, err
+			}
+			if v == cborBreak {
+				if !indefinite {
+					return nil, fmt.Errorf("cbor: unexpected break at offset %d", d.offset-1)
+				}
+				break
+			}
+			vs = append(vs, v)
+		}
+		if vs == nil {
+			vs = []any{}
+		}
+		return vs, nil
+	case 5:
+		return d.decodeMap(arg, indefinite)
+	case 6:
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		if v == cborBreak {
+			return nil, fmt.Errorf("cbor: unexpected break at offset %d", d.offset-1)
+		}
+		return d.decodeTag(arg, v)
+	default:
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25:
+			return float32FromHalf(uint16(arg)), nil
+		case 26:
+			return math.Float32frombits(uint32(arg)), nil
+		case 27:
+			return math.Float64frombits(arg), nil
+		case 31:
+			return cborBreak, nil
+		default:
+			return nil, fmt.Errorf("cbor: unsupported simple value %d", arg)
+		}
+	}
+}
+
+func (d *cborDecoder) decodeMap(n uint64, indefinite bool) (any, error) {
+	var keys, values []any
+	strKeys := true
+	for i := uint64(0); indefinite || i < n; i++ {
+		k, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		if k == cborBreak {
+			if !indefinite {
+				return nil, fmt.Errorf("cbor: unexpected break at offset %d", d.offset-1)
+			}
+			break
+		}
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		if v == cborBreak {
+			return nil, fmt.Errorf("cbor: unexpected break at offset %d", d.offset-1)
+		}
+		if _, ok := k.(string); !ok {
+			strKeys = false
+		}
+		keys, values = append(keys, k), append(values, v)
+	}
+	if strKeys {
+		m := make(map[string]any, len(keys))
+		for i, k := range keys {
+			m[k.(string)] = values[i]
+		}
+		return m, nil
+	}
+	m := make(map[any]any, len(keys))
+	for i, k := range keys {
+		switch k.(type) {
+		case []any, []byte, map[string]any, map[any]any, *big.Int:
+			return nil, fmt.Errorf("cbor: unhashable map key: %v", k)
+		}
+		m[k] = values[i]
+	}
+	return m, nil
+}
+
+func (d *cborDecoder) decodeTag(tag uint64, v any) (any, error) {
+	if decode, ok := d.tags[tag]; ok {
+		return decode(v)
+	}
+	switch tag {
+	case 0:
+		if s, ok := v.(string); ok {
+			return time.Parse(time.RFC3339Nano, s)
+		}
+	case 1:
+		switch v := v.(type) {
+		case int:
+			return time.Unix(int64(v), 0).UTC(), nil
+		case float32:
+			return cborEpochTime(float64(v)), nil
+		case float64:
+			return cborEpochTime(v), nil
+		}
+	case 2, 3:
+		if bs, ok := v.([]byte); ok {
+			i := new(big.Int).SetBytes(bs)
+			if tag == 3 {
+				i.Not(i)
+			}
+			return i, nil
+		}
+	default:
+		return v, nil
+	}
+	return nil, fmt.Errorf("cbor: invalid content of tag %d: %v", tag, v)
+}
+
+func cborEpochTime(f float64) time.Time {
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
+
+// float32FromHalf converts the IEEE 754 half-precision float.
+func float32FromHalf(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp, frac := uint32(h>>10)&0x1f, uint32(h)&0x3ff
+	switch {
+	case exp == 0x1f: // infinity or NaN
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	case exp != 0: // normal number
+		return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+	default: // zero or subnormal number
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	}
+}
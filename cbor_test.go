@@ -0,0 +1,124 @@
+package astgen_test
+
+import (
+	"fmt"
+	"go/printer"
+	"go/token"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildCBOR(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []byte
+		opts     []astgen.Option
+		expected string
+		err      string
+	}{
+		{
+			name:     "null",
+			data:     []byte{0xf6},
+			expected: `nil`,
+		},
+		{
+			name:     "integers",
+			data:     []byte{0x85, 0x01, 0x20, 0x38, 0x63, 0x19, 0x12, 0x34, 0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			expected: "[]interface {\n}{interface {\n}(1), interface {\n}(-1), interface {\n}(-100), interface {\n}(4660), interface {\n}(uint64(18446744073709551615))}",
+		},
+		{
+			name:     "floats",
+			data:     []byte{0x83, 0xf9, 0x3e, 0x00, 0xfa, 0x3f, 0xc0, 0x00, 0x00, 0xfb, 0x40, 0x09, 0x21, 0xfb, 0x54, 0x44, 0x2d, 0x18},
+			expected: "[]interface {\n}{interface {\n}(float32(1.5)), interface {\n}(float32(1.5)), interface {\n}(3.141592653589793)}",
+		},
+		{
+			name:     "map of string keys",
+			data:     []byte{0xa2, 0x61, 'a', 0xf5, 0x61, 'b', 0x42, 'h', 'i'},
+			expected: "map[string]interface {\n}{\"a\": interface {\n}(true), \"b\": interface {\n}([]uint8{uint8(104), uint8(105)})}",
+		},
+		{
+			name:     "map of integer keys",
+			data:     []byte{0xa2, 0x02, 0x61, 'b', 0x01, 0x61, 'a'},
+			expected: "map[interface {\n}]interface {\n}{interface {\n}(1): interface {\n}(\"a\"), interface {\n}(2): interface {\n}(\"b\")}",
+		},
+		{
+			name:     "indefinite-length items",
+			data:     []byte{0xbf, 0x61, 'a', 0x9f, 0x01, 0x02, 0xff, 0x61, 'b', 0x7f, 0x62, 'h', 'e', 0x63, 'l', 'l', 'o', 0xff, 0xff},
+			expected: "map[string]interface {\n}{\"a\": interface {\n}([]interface {\n}{interface {\n}(1), interface {\n}(2)}), \"b\": interface {\n}(\"hello\")}",
+		},
+		{
+			name:     "unknown tag",
+			data:     []byte{0xd8, 0x20, 0x63, 'u', 'r', 'l'},
+			expected: `"url"`,
+		},
+		{
+			name: "tag with hint",
+			data: []byte{0xd8, 0x20, 0x63, 'u', 'r', 'l'},
+			opts: []astgen.Option{
+				astgen.WithCBORTag(32, func(v any) (any, error) {
+					return "https://" + v.(string), nil
+				}),
+			},
+			expected: `"https://url"`,
+		},
+		{
+			name: "unexpected end of data",
+			data: []byte{0x82, 0x01},
+			err:  "cbor: unexpected end of data",
+		},
+		{
+			name: "unexpected break",
+			data: []byte{0x82, 0x01, 0xff},
+			err:  "cbor: unexpected break at offset 2",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.BuildCBOR(tc.data, tc.opts...)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestDecodeCBORTags(t *testing.T) {
+	got, err := astgen.DecodeCBOR([]byte{
+		0x84,
+		0xc0, 0x74, '2', '0', '1', '3', '-', '0', '3', '-', '2', '1', 'T', '2', '0', ':', '0', '4', ':', '0', '0', 'Z',
+		0xc1, 0x1a, 0x51, 0x4b, 0x67, 0xb0,
+		0xc2, 0x49, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xc3, 0x49, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}, nil)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	vs := got.([]any)
+	if expected := time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC); !vs[0].(time.Time).Equal(expected) {
+		t.Errorf("expected: %v\ngot: %v", expected, vs[0])
+	}
+	if expected := time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC); !vs[1].(time.Time).Equal(expected) {
+		t.Errorf("expected: %v\ngot: %v", expected, vs[1])
+	}
+	if expected := "18446744073709551616"; vs[2].(*big.Int).String() != expected {
+		t.Errorf("expected: %s\ngot: %v", expected, vs[2])
+	}
+	if expected := "-18446744073709551617"; fmt.Sprint(vs[3]) != expected {
+		t.Errorf("expected: %s\ngot: %v", expected, vs[3])
+	}
+}
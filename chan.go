@@ -0,0 +1,33 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+)
+
+// WithMakeChan makes Build emit the channels as the calls of make like
+// make(chan T) and make(chan T, 10), with the capacity of the channels. The
+// elements buffered in the channels are not built, and the channels shared
+// by the values are made for each occurrence.
+func WithMakeChan() Option {
+	return func(b *builder) {
+		b.makeChan = true
+	}
+}
+
+func (b *builder) buildChan(v reflect.Value) (ast.Expr, error) {
+	if v.IsNil() {
+		return &ast.Ident{Name: "nil"}, nil
+	}
+	t, err := b.buildType(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	args := []ast.Expr{t}
+	if c := v.Cap(); c > 0 {
+		args = append(args, &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(c)})
+	}
+	return &ast.CallExpr{Fun: &ast.Ident{Name: "make"}, Args: args}, nil
+}
@@ -0,0 +1,81 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithMakeChan(t *testing.T) {
+	type T struct {
+		Done    chan bool
+		Jobs    chan<- int
+		Results <-chan []string
+		Errs    chan error
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "unbuffered",
+			src:      make(chan int),
+			expected: `make(chan int)`,
+		},
+		{
+			name:     "buffered",
+			src:      make(chan string, 10),
+			expected: `make(chan string, 10)`,
+		},
+		{
+			name:     "nil",
+			src:      []chan int{nil, make(chan int, 1)},
+			expected: `[]chan int{nil, make(chan int, 1)}`,
+		},
+		{
+			name: "nil in interface",
+			src:  []any{(chan int)(nil)},
+			expected: `[]interface {
+}{interface {
+}((chan int)(nil))}`,
+		},
+		{
+			name:     "struct",
+			src:      T{Done: make(chan bool), Jobs: make(chan int, 3), Results: make(<-chan []string)},
+			expected: `T{Done: make(chan bool), Jobs: make(chan<- int, 3), Results: make(<-chan []string)}`,
+		},
+		{
+			name: "pointer",
+			src:  []*chan int{new(chan int)},
+			expected: `(func(n chan int) []*chan int {
+	return []*chan int{&n}
+})(nil)`,
+		},
+		{
+			name:     "channel of channels",
+			src:      [](chan (<-chan int)){make(chan (<-chan int), 2)},
+			expected: `[]chan (<-chan int){make(chan (<-chan int), 2)}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, astgen.WithMakeChan())
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+			if err := astgen.Check(tc.src, astgen.WithMakeChan()); err != nil {
+				t.Errorf("should not return error: %s", err)
+			}
+		})
+	}
+}
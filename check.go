@@ -0,0 +1,278 @@
+package astgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Check builds x and checks the generated code; it parses the code, type
+// checks it with the declarations of the named types, evaluates it back by
+// Eval, and compares the value with x. This detects the literals which are
+// not valid Go code, or do not represent the value exactly. The expressions
+// of the registered functions and the hooks should be self-contained. The
+// expressions built by the builtin rules, like time.Date and
+// netip.MustParseAddr, are evaluated if they consist of the constants and the
+// functions of the standard library known to Eval. The other expressions of
+// the builtin rules are only type checked, and evaluated as the original
+// values.
+func Check(x any, opts ...Option) error {
+	v, t := reflect.ValueOf(x), reflect.TypeOf(&x).Elem()
+	if v.IsValid() {
+		t = v.Type()
+	}
+	b := newBuilder(opts)
+	b.ruleValues = make(map[string]reflect.Value)
+	n, err := b.buildNode(v)
+	if err != nil {
+		return err
+	}
+	src := printNode(n)
+	checkError := func(step string, err error) error {
+		return &CheckError{Type: t, Step: step, Source: src, Err: err}
+	}
+	fset := token.NewFileSet()
+	e, err := parser.ParseExprFrom(fset, "", src, 0)
+	if err != nil {
+		return checkError("parse", err)
+	}
+	named := b.namedTypes(v)
+	if err := b.typeCheck(fset, e, t, named); err != nil {
+		return checkError("type check", err)
+	}
+	ev := &evaluator{types: maps.Clone(named), values: b.ruleValues}
+	for _, t := range named { // the builtin rules refer to the qualified names
+		if _, ok := ev.types[t.String()]; !ok {
+			ev.types[t.String()] = t
+		}
+	}
+	ev.collectTypes(t)
+	w := reflect.New(t).Elem()
+	if err := ev.eval(e, w, nil); err != nil {
+		return checkError("eval", err)
+	}
+	if reflect.DeepEqual(w.Interface(), x) {
+		return nil
+	}
+	m, err := newBuilder(opts).buildNode(w)
+	if err != nil {
+		return checkError("compare", err)
+	}
+	if s := printNode(m); s != src {
+		return checkError("compare", fmt.Errorf("evaluated value differs:\n%s",
+			DiffLines("value", "evaluated", src, s)))
+	}
+	if neverDeepEqual(v) {
+		return nil
+	}
+	return checkError("compare", errors.New("evaluated value differs"))
+}
+
+// namedTypes collects the named types of the value including the dynamic
+// types of the interfaces, and the types of the aliases, by the names in the
+// generated code.
+func (b *builder) namedTypes(v reflect.Value) map[string]reflect.Type {
+	named := make(map[string]reflect.Type)
+	seen := make(map[reflect.Type]bool)
+	var walkType func(reflect.Type)
+	walkType = func(t reflect.Type) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		if t.Name() != "" && t.PkgPath() != "" {
+			named[printNode(b.typeName(t))] = t
+		}
+		switch t.Kind() {
+		case reflect.Array, reflect.Slice, reflect.Ptr, reflect.Chan:
+			walkType(t.Elem())
+		case reflect.Map:
+			walkType(t.Key())
+			walkType(t.Elem())
+		case reflect.Struct:
+			for i := 0; i < t.NumField(); i++ {
+				walkType(t.Field(i).Type)
+			}
+		}
+	}
+	var walk func(reflect.Value)
+	walk = func(v reflect.Value) {
+		if !v.IsValid() {
+			return
+		}
+		walkType(v.Type())
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr:
+			if !v.IsNil() {
+				walk(v.Elem())
+			}
+		case reflect.Array, reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Map:
+			for iter := v.MapRange(); iter.Next(); {
+				walk(iter.Key())
+				walk(iter.Value())
+			}
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				walk(v.Field(i))
+			}
+		}
+	}
+	imports := maps.Clone(b.imports) // the names of the unused types are not imported
+	walk(v)
+	b.imports = imports
+	for t, e := range b.typeAliases {
+		if e, ok := e.(*ast.Ident); ok {
+			named[e.Name] = t
+		}
+	}
+	return named
+}
+
+// neverDeepEqual reports whether the value contains NaN or the channels, which
+// are not deeply equal to the values evaluated back.
+func neverDeepEqual(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Chan:
+		return !v.IsNil()
+	case reflect.Float32, reflect.Float64:
+		return math.IsNaN(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return math.IsNaN(real(c)) || math.IsNaN(imag(c))
+	case reflect.Interface, reflect.Ptr:
+		return !v.IsNil() && neverDeepEqual(v.Elem())
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if neverDeepEqual(v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		for iter := v.MapRange(); iter.Next(); {
+			if neverDeepEqual(iter.Key()) || neverDeepEqual(iter.Value()) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if neverDeepEqual(v.Field(i)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeCheck type checks the expression as a value of t, in a file declaring
+// the named types referred by the unqualified names, and importing the other
+// packages. The types of the importable packages are declared as the aliases,
+// so that the values built by the builtin rules have the identical types.
+func (b *builder) typeCheck(fset *token.FileSet, e ast.Expr, t reflect.Type, named map[string]reflect.Type) error {
+	typ, err := b.buildType(t)
+	if err != nil {
+		return err
+	}
+	var names []string
+	declared := make(map[string]bool)
+	refer := func(n ast.Node) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr: // qualified by the imported package
+				return false
+			case *ast.Ident:
+				if _, ok := named[n.Name]; ok && !declared[n.Name] {
+					declared[n.Name] = true
+					names = append(names, n.Name)
+				}
+			}
+			return true
+		})
+	}
+	refer(e)
+	refer(typ)
+	specs := make(map[string]*ast.TypeSpec)
+	for i := 0; i < len(names); i++ {
+		name, t := names[i], named[names[i]]
+		if path := t.PkgPath(); token.IsExported(t.Name()) && (b.imports[path] || isStdPackage(path)) {
+			specs[name] = &ast.TypeSpec{
+				Name: &ast.Ident{Name: name}, Assign: 1, Type: b.selector(path, t.Name()),
+			}
+			continue
+		}
+		typ, err := b.buildTypeLit(t)
+		if err != nil {
+			return err
+		}
+		specs[name] = &ast.TypeSpec{Name: &ast.Ident{Name: name}, Type: typ}
+		refer(typ)
+	}
+	sort.Strings(names)
+	d := &ast.GenDecl{Tok: token.TYPE}
+	for _, name := range names {
+		d.Specs = append(d.Specs, specs[name])
+	}
+	f := &ast.File{Name: &ast.Ident{Name: "check"}}
+	if len(b.imports) > 0 {
+		paths := make([]string, 0, len(b.imports))
+		for path := range b.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		d := &ast.GenDecl{Tok: token.IMPORT}
+		for _, path := range paths {
+			d.Specs = append(d.Specs, &ast.ImportSpec{
+				Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)},
+			})
+		}
+		f.Decls = append(f.Decls, d)
+	}
+	if len(d.Specs) > 0 {
+		f.Decls = append(f.Decls, d)
+	}
+	f.Decls = append(f.Decls, &ast.GenDecl{
+		Tok: token.VAR,
+		Specs: []ast.Spec{
+			&ast.ValueSpec{Names: []*ast.Ident{{Name: "_"}}, Type: typ, Values: []ast.Expr{e}},
+		},
+	})
+	conf := &types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("check", fset, []*ast.File{f}, nil)
+	return err
+}
+
+// isStdPackage reports whether the package is in the standard library, by the
+// first element of the path without a dot.
+func isStdPackage(pkgPath string) bool {
+	elem, _, _ := strings.Cut(pkgPath, "/")
+	return elem != "" && elem != "main" && !strings.Contains(elem, ".")
+}
+
+// CheckError is the error of Check when the generated code is invalid.
+type CheckError struct {
+	Type   reflect.Type
+	Step   string // parse, type check, eval or compare
+	Source string // generated code
+	Err    error
+}
+
+func (err *CheckError) Error() string {
+	return fmt.Sprintf("check of %s failed in %s: %s", err.Type, err.Step, err.Err)
+}
+
+func (err *CheckError) Unwrap() error {
+	return err.Err
+}
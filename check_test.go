@@ -0,0 +1,145 @@
+package astgen_test
+
+import (
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"math"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestCheck(t *testing.T) {
+	type port int
+	type Y struct {
+		Ports []port
+	}
+	type T struct {
+		Name    string
+		Y       *Y
+		Attrs   map[string]any
+		Timeout time.Duration
+	}
+	type Z struct {
+		At     time.Time
+		Number json.Number
+		Addr   netip.Addr
+		Prefix *netip.Prefix
+	}
+	x := 42
+	testCases := []struct {
+		name string
+		x    any
+		opts []astgen.Option
+		err  string
+	}{
+		{
+			name: "struct",
+			x: T{Name: "foo", Y: &Y{Ports: []port{80, 443}},
+				Attrs: map[string]any{"x": 1, "y": []any{Y{}, &x, nil}}, Timeout: time.Second},
+		},
+		{
+			name: "qualified",
+			x:    []time.Duration{time.Minute},
+			opts: []astgen.Option{astgen.WithCurrentPackage("github.com/itchyny/astgen-go_test")},
+		},
+		{
+			name: "types of other packages",
+			x:    []any{Z{}, Z{Number: "1"}, json.Number("2"), []time.Duration{time.Hour}},
+		},
+		{
+			name: "builtin rules",
+			x: Z{At: time.Date(2020, 1, 2, 3, 4, 5, 6, time.FixedZone("JST", 9*60*60)),
+				Addr: netip.MustParseAddr("::1"), Number: "3"},
+		},
+		{
+			name: "builtin rules in main",
+			x:    []any{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), netip.MustParseAddr("127.0.0.1")},
+			opts: []astgen.Option{astgen.WithCurrentPackage("main")},
+		},
+		{
+			name: "nil",
+			x:    nil,
+		},
+		{
+			name: "defined types",
+			x:    map[string]any{"port": port(80), "ports": []any{port(443)}},
+		},
+		{
+			name: "NaN",
+			x:    math.NaN(),
+		},
+		{
+			name: "special floats",
+			x:    []any{math.Inf(1), float32(math.Inf(-1)), []float64{1e300, 1e-7, math.NaN()}},
+		},
+		{
+			name: "special complexes",
+			x:    []any{complex64(complex(math.Inf(1), 0)), []complex128{complex(math.NaN(), 1.5), 1 + 2i}},
+		},
+		{
+			name: "builtin rule types",
+			x:    []any{struct{ D time.Duration }{time.Second}, map[string]netip.Addr{}},
+		},
+		{
+			name: "infinite complex",
+			x:    complex(1, math.Inf(-1)),
+		},
+		{
+			name: "undefined",
+			x:    Y{Ports: []port{80}},
+			opts: []astgen.Option{astgen.WithAfterExpr(func(v reflect.Value, e ast.Expr) (ast.Expr, error) {
+				if v.Kind() == reflect.Int {
+					return &ast.CallExpr{Fun: &ast.Ident{Name: "parsePort"}, Args: []ast.Expr{e}}, nil
+				}
+				return e, nil
+			})},
+			err: "check of astgen_test.Y failed in type check: 1:17: undefined: parsePort",
+		},
+		{
+			name: "defaults",
+			x:    Y{Ports: []port{80}},
+			opts: []astgen.Option{astgen.WithDefaults(Y{Ports: []port{80}})},
+			err: `check of astgen_test.Y failed in compare: evaluated value differs:
+--- value
++++ evaluated
+-Y{}
++Y{Ports: []port{}}
+`,
+		},
+		{
+			name: "differs",
+			x:    Y{Ports: []port{80}},
+			opts: []astgen.Option{astgen.WithBeforeValue(func(v reflect.Value) (reflect.Value, error) {
+				if v.Kind() == reflect.Int {
+					return reflect.ValueOf(port(8080)), nil
+				}
+				return v, nil
+			})},
+			err: "check of astgen_test.Y failed in compare: evaluated value differs",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := astgen.Check(tc.x, tc.opts...)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("should not return error: %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("expected: %s\ngot: %v", tc.err, err)
+			}
+			var e *astgen.CheckError
+			if !errors.As(err, &e) || e.Source == "" {
+				t.Errorf("should return CheckError: %#v", err)
+			}
+		})
+	}
+}
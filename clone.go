@@ -0,0 +1,56 @@
+package astgen
+
+import (
+	"go/ast"
+	"reflect"
+)
+
+// cloneNode returns a deep copy of the node, preserving the sharing of nodes.
+func cloneNode(n ast.Node) ast.Node {
+	if n == nil {
+		return nil
+	}
+	return cloneValue(reflect.ValueOf(n), make(map[any]reflect.Value)).Interface().(ast.Node)
+}
+
+func cloneValue(v reflect.Value, seen map[any]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if w, ok := seen[v.Interface()]; ok {
+			return w
+		}
+		w := reflect.New(v.Type().Elem())
+		seen[v.Interface()] = w
+		w.Elem().Set(cloneValue(v.Elem(), seen))
+		return w
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		w := reflect.New(v.Type()).Elem()
+		w.Set(cloneValue(v.Elem(), seen))
+		return w
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		w := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			w.Index(i).Set(cloneValue(v.Index(i), seen))
+		}
+		return w
+	case reflect.Struct:
+		w := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if w.Field(i).CanSet() {
+				w.Field(i).Set(cloneValue(v.Field(i), seen))
+			}
+		}
+		return w
+	default:
+		return v
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/itchyny/astgen-go"
+)
+
+const name = "astgen"
+
+const (
+	exitCodeOK = iota
+	exitCodeErr
+	exitCodeStale
+)
+
+type cli struct {
+	inStream  io.Reader
+	outStream io.Writer
+	errStream io.Writer
+	now       func() time.Time
+}
+
+func (cli *cli) run(args []string) int {
+	if len(args) > 0 && args[0] == "fs" {
+		return cli.runFS(args[1:])
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(cli.errStream)
+	fs.Usage = func() {
+		fmt.Fprintf(cli.errStream, "Usage: %s [flags] [file...]\n       %s fs [flags] dir\n", name, name)
+		fs.PrintDefaults()
+	}
+	var (
+		output  = fs.String("o", "", "output file (default: stdout)")
+		pkg     = fs.String("pkg", "main", "package name")
+		varName = fs.String("name", "data", "variable name")
+		doc     = fs.String("doc", "", "doc comment of the variable")
+		format  = fs.String("format", "", "input format: json, xml, msgpack or cbor (default: by the file extension or json)")
+		check   = fs.Bool("check", false, "check the output file is up to date without writing")
+		number  = fs.String("json-number", "float64", "type of JSON numbers: float64, int, int64 or raw (json.Number)")
+		header  headerFlags
+	)
+	header.register(fs, true)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitCodeOK
+		}
+		return exitCodeErr
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+	if len(paths) > 1 {
+		var single bool
+		fs.Visit(func(f *flag.Flag) { single = single || f.Name == "name" || f.Name == "doc" })
+		if single {
+			fmt.Fprintf(cli.errStream, "%s: -name and -doc require a single input\n", name)
+			return exitCodeErr
+		}
+	}
+	if *check && *output == "" {
+		fmt.Fprintf(cli.errStream, "%s: -check requires -o\n", name)
+		return exitCodeErr
+	}
+	t := &target{pkg: *pkg}
+	inputs := make([]input, len(paths))
+	values := make([]any, len(paths))
+	names := make(map[string]bool, len(paths))
+	imports := make(map[string]bool)
+	for i, path := range paths {
+		v := variable{name: *varName, doc: *doc}
+		if len(paths) > 1 {
+			var err error
+			if v.name, err = variableName(path); err != nil {
+				fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+				return exitCodeErr
+			}
+			if names[v.name] {
+				fmt.Fprintf(cli.errStream, "%s: duplicate variable name %s of %s\n", name, v.name, path)
+				return exitCodeErr
+			}
+			names[v.name] = true
+		}
+		data, err := cli.readInput(path)
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+			return exitCodeErr
+		}
+		inputs[i] = input{path, data}
+		if values[i], err = decodeInput(data, path, *format, *number); err != nil {
+			fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+			return exitCodeErr
+		}
+		t.vars = append(t.vars, v)
+	}
+	builder := astgen.NewBuilder()
+	for i := range t.vars {
+		n, err := builder.Build(values[i])
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+			return exitCodeErr
+		}
+		t.vars[i].expr = n.(ast.Expr)
+		for _, path := range builder.Imports() {
+			imports[path] = true
+		}
+	}
+	if err := header.apply(t, cli.now, inputs); err != nil {
+		fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+		return exitCodeErr
+	}
+	importPaths := make([]string, 0, len(imports))
+	for path := range imports {
+		importPaths = append(importPaths, path)
+	}
+	sort.Strings(importPaths)
+	if *check {
+		return cli.checkOutput(*output, t, importPaths...)
+	}
+	return cli.writeOutput(*output, t, importPaths...)
+}
+
+// variableName derives the variable name from the base name of the file,
+// joining the words in camel case; user-list.json is named userList.
+func variableName(path string) (string, error) {
+	base, _, _ := strings.Cut(filepath.Base(path), ".")
+	var sb strings.Builder
+	for i, w := range strings.FieldsFunc(base, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if i > 0 {
+			r, size := utf8.DecodeRuneInString(w)
+			sb.WriteRune(unicode.ToUpper(r))
+			w = w[size:]
+		}
+		sb.WriteString(w)
+	}
+	if s := sb.String(); token.IsIdentifier(s) {
+		return s, nil
+	}
+	return "", fmt.Errorf("cannot derive variable name from %s", path)
+}
+
+// target is the configuration of the generated file.
+type target struct {
+	pkg        string
+	vars       []variable
+	license    string
+	provenance []string
+}
+
+// variable is a variable declaration in the generated file.
+type variable struct {
+	name, doc string
+	expr      ast.Expr
+}
+
+// input is the contents of an input file.
+type input struct {
+	path string
+	data []byte
+}
+
+func (cli *cli) writeOutput(output string, t *target, imports ...string) int {
+	src, err := generate(t, imports...)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+		return exitCodeErr
+	}
+	if output == "" {
+		_, err = cli.outStream.Write(src)
+	} else {
+		err = os.WriteFile(output, src, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+		return exitCodeErr
+	}
+	return exitCodeOK
+}
+
+// checkOutput compares the output file with the generated source byte by
+// byte, and reports the difference if the file is stale. The generation time
+// in the header is ignored, because it always differs.
+func (cli *cli) checkOutput(output string, t *target, imports ...string) int {
+	src, err := generate(t, imports...)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+		return exitCodeErr
+	}
+	got, err := os.ReadFile(output)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+		return exitCodeErr
+	}
+	got, src = maskTimestamp(got), maskTimestamp(src)
+	if bytes.Equal(got, src) {
+		return exitCodeOK
+	}
+	fmt.Fprintf(cli.errStream, "%s: %s is stale:\n%s", name, output,
+		astgen.DiffLines(output, "generated", string(got), string(src)))
+	return exitCodeStale
+}
+
+func (cli *cli) readInput(path string) ([]byte, error) {
+	if path != "" && path != "-" {
+		return os.ReadFile(path)
+	}
+	return io.ReadAll(cli.inStream)
+}
+
+func decodeInput(data []byte, path, format, number string) (any, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	switch format {
+	case "xml":
+		return astgen.DecodeXML(bytes.NewReader(data))
+	case "msgpack", "mp":
+		return astgen.DecodeMsgpack(data, nil)
+	case "cbor":
+		return astgen.DecodeCBOR(data, nil)
+	case "json", "":
+		mode, ok := map[string]astgen.JSONNumberMode{
+			"float64": astgen.JSONNumberFloat64,
+			"int":     astgen.JSONNumberInt,
+			"int64":   astgen.JSONNumberInt64,
+			"raw":     astgen.JSONNumberRaw,
+		}[number]
+		if !ok {
+			return nil, fmt.Errorf("unknown JSON number type: %s", number)
+		}
+		// The integers which cannot be decoded exactly are kept as json.Number.
+		return astgen.DecodeJSON(bytes.NewReader(data), mode, astgen.JSONBigIntRaw)
+	default:
+		return nil, fmt.Errorf("unknown input format: %s", format)
+	}
+}
+
+func generate(t *target, imports ...string) ([]byte, error) {
+	var decls []ast.Decl
+	if len(imports) > 0 {
+		d := &ast.GenDecl{Tok: token.IMPORT}
+		for _, path := range imports {
+			d.Specs = append(d.Specs, &ast.ImportSpec{
+				Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)},
+			})
+		}
+		decls = append(decls, d)
+	}
+	docs := make(map[ast.Decl]string, len(t.vars))
+	for _, v := range t.vars {
+		d := &ast.GenDecl{
+			Tok: token.VAR,
+			Specs: []ast.Spec{
+				&ast.ValueSpec{
+					Names:  []*ast.Ident{{Name: v.name}},
+					Values: []ast.Expr{v.expr},
+				},
+			},
+		}
+		docs[d] = v.doc
+		decls = append(decls, d)
+	}
+	var buf bytes.Buffer
+	if t.license != "" {
+		buf.WriteString(t.license + "\n")
+	}
+	buf.WriteString("// Code generated by " + name + "; DO NOT EDIT.\n")
+	for _, s := range t.provenance {
+		buf.WriteString("// " + s + "\n")
+	}
+	buf.WriteString("\npackage " + t.pkg + "\n")
+	// Print the declarations one by one to separate them by blank lines, and
+	// the doc comments before the variables, because the nodes have no positions.
+	for _, d := range decls {
+		buf.WriteString("\n")
+		if g := astgen.DocComment(docs[d]); g != nil {
+			for _, c := range g.List {
+				buf.WriteString(c.Text + "\n")
+			}
+		}
+		if err := format.Node(&buf, token.NewFileSet(), d); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCliRun(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "data.go")
+	run := func(input string, args ...string) (int, string, string) {
+		var outStream, errStream strings.Builder
+		code := (&cli{
+			inStream:  strings.NewReader(input),
+			outStream: &outStream,
+			errStream: &errStream,
+			now:       func() time.Time { return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC) },
+		}).run(args)
+		return code, outStream.String(), errStream.String()
+	}
+
+	code, out, _ := run(`{"foo": [1, "bar", null]}`, "-pkg", "data")
+	expected := `// Code generated by astgen; DO NOT EDIT.
+
+package data
+
+var data = map[string]interface {
+}{"foo": interface {
+}([]interface {
+}{interface {
+}(1.0), interface {
+}("bar"), interface {
+}(nil)})}
+`
+	if code != exitCodeOK || out != expected {
+		t.Fatalf("expected: %d %s\ngot: %d %s", exitCodeOK, expected, code, out)
+	}
+	if err := os.WriteFile(output, []byte(out), 0o644); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+
+	code, _, errOut := run(`{"foo": [1, "bar", null]}`, "-check", "-pkg", "data", "-o", output)
+	if code != exitCodeOK || errOut != "" {
+		t.Errorf("expected: %d\ngot: %d %s", exitCodeOK, code, errOut)
+	}
+
+	code, _, errOut = run(`{"foo": [1, "baz", null]}`, "-check", "-pkg", "data", "-o", output)
+	if code != exitCodeStale || !strings.Contains(errOut, "data.go is stale:\n") ||
+		!strings.Contains(errOut, "\n-}(\"bar\"), interface {\n+}(\"baz\"), interface {\n") {
+		t.Errorf("expected: %d\ngot: %d %s", exitCodeStale, code, errOut)
+	}
+	if got, _ := os.ReadFile(output); string(got) != out {
+		t.Errorf("should not write the output file: %s", got)
+	}
+
+	code, _, errOut = run(`{"foo": [1, "bar", null]}`, "-check", "-pkg", "data2", "-o", output)
+	if code != exitCodeStale || !strings.Contains(errOut, "\n-package data\n+package data2\n") {
+		t.Errorf("expected: %d\ngot: %d %s", exitCodeStale, code, errOut)
+	}
+
+	code, _, errOut = run(`{"foo": [1, "bar", null]}`, "-check", "-o", output+".missing")
+	if code != exitCodeErr || !strings.Contains(errOut, "no such file or directory") {
+		t.Errorf("expected: %d\ngot: %d %s", exitCodeErr, code, errOut)
+	}
+
+	if code, _, errOut = run(`[1]`, "-timestamp", "-o", output); code != exitCodeOK {
+		t.Fatalf("expected: %d\ngot: %d %s", exitCodeOK, code, errOut)
+	}
+	var errStream strings.Builder
+	code = (&cli{
+		inStream:  strings.NewReader(`[1]`),
+		errStream: &errStream,
+		now:       func() time.Time { return time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}).run([]string{"-timestamp", "-check", "-o", output})
+	if code != exitCodeOK {
+		t.Errorf("expected: %d\ngot: %d %s", exitCodeOK, code, errStream.String())
+	}
+	if code, _, errOut = run(`[1]`, "-check", "-o", output); code != exitCodeStale ||
+		!strings.Contains(errOut, "\n-// Generated at: \n") {
+		t.Errorf("expected: %d\ngot: %d %s", exitCodeStale, code, errOut)
+	}
+
+	code, out, _ = run(`<root><foo>bar</foo></root>`, "-format", "xml")
+	expected = `// Code generated by astgen; DO NOT EDIT.
+
+package main
+
+var data = map[string]interface {
+}{"root": interface {
+}(map[string]interface {
+}{"foo": interface {
+}("bar")})}
+`
+	if code != exitCodeOK || out != expected {
+		t.Errorf("expected: %d %s\ngot: %d %s", exitCodeOK, expected, code, out)
+	}
+
+	code, out, _ = run("\x81\xa3foo\x92\x01\xc3", "-format", "msgpack")
+	expected = `// Code generated by astgen; DO NOT EDIT.
+
+package main
+
+var data = map[string]interface {
+}{"foo": interface {
+}([]interface {
+}{interface {
+}(1), interface {
+}(true)})}
+`
+	if code != exitCodeOK || out != expected {
+		t.Errorf("expected: %d %s\ngot: %d %s", exitCodeOK, expected, code, out)
+	}
+
+	code, out, _ = run("\xa1\x63foo\x82\x01\xf5", "-format", "cbor")
+	if code != exitCodeOK || out != expected {
+		t.Errorf("expected: %d %s\ngot: %d %s", exitCodeOK, expected, code, out)
+	}
+
+	code, out, _ = run("\x82\xc1\x1a\x5e\x0b\xe1\x00\xc2\x49\x01\x00\x00\x00\x00\x00\x00\x00\x00", "-format", "cbor")
+	expected = `// Code generated by astgen; DO NOT EDIT.
+
+package main
+
+import (
+	"math/big"
+	"time"
+)
+
+var data = []interface {
+}{interface {
+}(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)), interface {
+}(func() *big.Int {
+	x, _ := new(big.Int).SetString("18446744073709551616", 10)
+	return x
+}())}
+`
+	if code != exitCodeOK || out != expected {
+		t.Errorf("expected: %d %s\ngot: %d %s", exitCodeOK, expected, code, out)
+	}
+
+	code, out, _ = run(`[1]`, "-name", "Data", "-doc", "Data is generated from data.json.\n\nDO NOT EDIT.")
+	expected = `// Code generated by astgen; DO NOT EDIT.
+
+package main
+
+// Data is generated from data.json.
+//
+// DO NOT EDIT.
+var Data = []interface {
+}{interface {
+}(1.0)}
+`
+	if code != exitCodeOK || out != expected {
+		t.Errorf("expected: %d %s\ngot: %d %s", exitCodeOK, expected, code, out)
+	}
+
+	header := filepath.Join(t.TempDir(), "LICENSE")
+	if err := os.WriteFile(header, []byte("Copyright 2020 The Authors.\nAll rights reserved.\n"), 0o644); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	code, out, _ = run(`[1]`, "-header", header, "-provenance", "-timestamp")
+	expected = `// Copyright 2020 The Authors.
+// All rights reserved.
+
+// Code generated by astgen; DO NOT EDIT.
+// Source: <stdin> (sha256:080a9ed428559ef602668b4c00f114f1a11c3f6b02a435f0bdc154578e4d7f22)
+// Generated at: 2020-01-02T03:04:05Z
+
+package main
+
+var data = []interface {
+}{interface {
+}(1.0)}
+`
+	if code != exitCodeOK || out != expected {
+		t.Errorf("expected: %d %s\ngot: %d %s", exitCodeOK, expected, code, out)
+	}
+
+	code, out, _ = run(`[1, 2.5, 18446744073709551616]`, "-json-number", "int")
+	expected = `// Code generated by astgen; DO NOT EDIT.
+
+package main
+
+import "encoding/json"
+
+var data = []interface {
+}{interface {
+}(1), interface {
+}(2.5), interface {
+}(json.Number("18446744073709551616"))}
+`
+	if code != exitCodeOK || out != expected {
+		t.Errorf("expected: %d %s\ngot: %d %s", exitCodeOK, expected, code, out)
+	}
+
+	code, out, _ = run(`[1e6, 1e21, 1e-7]`)
+	expected = `// Code generated by astgen; DO NOT EDIT.
+
+package main
+
+var data = []interface {
+}{interface {
+}(1e+06), interface {
+}(1e+21), interface {
+}(1e-07)}
+`
+	if code != exitCodeOK || out != expected {
+		t.Errorf("expected: %d %s\ngot: %d %s", exitCodeOK, expected, code, out)
+	}
+
+	code, _, errOut = run(`{}`, "-format", "yaml")
+	if code != exitCodeErr || !strings.Contains(errOut, "unknown input format: yaml") {
+		t.Errorf("expected: %d\ngot: %d %s", exitCodeErr, code, errOut)
+	}
+}
+
+func TestCliRunFS(t *testing.T) {
+	dir := t.TempDir()
+	for path, data := range map[string]string{
+		"a.txt":          "a\n",
+		"b.json":         "{}\n",
+		"sub/c.txt":      "c\n",
+		"sub/d.txt":      "d\n",
+		"vendor/e.txt":   "e\n",
+		"sub/deep/f.txt": "f\n",
+	} {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("should not return error: %s", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("should not return error: %s", err)
+		}
+	}
+	var outStream, errStream strings.Builder
+	code := (&cli{outStream: &outStream, errStream: &errStream}).run([]string{
+		"fs", "-pkg", "testdata", "-include", "*.txt",
+		"-exclude", "vendor", "-exclude", "sub/d.txt", dir,
+	})
+	expected := `// Code generated by astgen; DO NOT EDIT.
+
+package testdata
+
+import "testing/fstest"
+
+var files = fstest.MapFS{"a.txt": {Data: []byte("a\n"), Mode: 0o644}, "sub/c.txt": {Data: []byte("c\n"), Mode: 0o644}, "sub/deep/f.txt": {Data: []byte("f\n"), Mode: 0o644}}
+`
+	// The file modes depend on the platform.
+	got := regexp.MustCompile(`Mode: 0o\d+`).ReplaceAllString(outStream.String(), "Mode: 0o644")
+	if code != exitCodeOK || got != expected {
+		t.Errorf("expected: %d %s\ngot: %d %s%s", exitCodeOK, expected, code, got, errStream.String())
+	}
+}
+
+func TestCliRunMultipleInputs(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, file := range []struct{ path, data string }{
+		{"config.json", `{"debug": true, "port": 18446744073709551616}`},
+		{"user-list.xml", `<users><user>alice</user></users>`},
+		{"sizes.cbor", "\x82\x01\x02"},
+	} {
+		path := filepath.Join(dir, file.path)
+		if err := os.WriteFile(path, []byte(file.data), 0o644); err != nil {
+			t.Fatalf("should not return error: %s", err)
+		}
+		paths = append(paths, path)
+	}
+	output := filepath.Join(dir, "data.go")
+	var outStream, errStream strings.Builder
+	code := (&cli{outStream: &outStream, errStream: &errStream}).run(
+		append([]string{"-pkg", "testdata", "-o", output}, paths...),
+	)
+	expected := `// Code generated by astgen; DO NOT EDIT.
+
+package testdata
+
+import "encoding/json"
+
+var config = map[string]interface {
+}{"debug": interface {
+}(true), "port": interface {
+}(json.Number("18446744073709551616"))}
+
+var userList = map[string]interface {
+}{"users": interface {
+}(map[string]interface {
+}{"user": interface {
+}("alice")})}
+
+var sizes = []interface {
+}{interface {
+}(1), interface {
+}(2)}
+`
+	got, _ := os.ReadFile(output)
+	if code != exitCodeOK || string(got) != expected {
+		t.Fatalf("expected: %d %s\ngot: %d %s%s", exitCodeOK, expected, code, got, errStream.String())
+	}
+
+	code = (&cli{outStream: &outStream, errStream: &errStream}).run(
+		append([]string{"-check", "-pkg", "testdata", "-o", output}, paths...),
+	)
+	if code != exitCodeOK {
+		t.Errorf("expected: %d\ngot: %d %s", exitCodeOK, code, errStream.String())
+	}
+
+	errStream.Reset()
+	code = (&cli{outStream: &outStream, errStream: &errStream}).run(
+		append([]string{"-name", "data"}, paths...),
+	)
+	if code != exitCodeErr || !strings.Contains(errStream.String(), "-name and -doc require a single input") {
+		t.Errorf("expected: %d\ngot: %d %s", exitCodeErr, code, errStream.String())
+	}
+
+	errStream.Reset()
+	code = (&cli{outStream: &outStream, errStream: &errStream}).run(
+		[]string{paths[0], filepath.Join(dir, "config.xml")},
+	)
+	if code != exitCodeErr || !strings.Contains(errStream.String(), "duplicate variable name config") {
+		t.Errorf("expected: %d\ngot: %d %s", exitCodeErr, code, errStream.String())
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing/fstest"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func (cli *cli) runFS(args []string) int {
+	fs := flag.NewFlagSet(name+" fs", flag.ContinueOnError)
+	fs.SetOutput(cli.errStream)
+	fs.Usage = func() {
+		fmt.Fprintf(cli.errStream, "Usage: %s fs [flags] dir\n", name)
+		fs.PrintDefaults()
+	}
+	var includes, excludes patternsFlag
+	var (
+		output  = fs.String("o", "", "output file (default: stdout)")
+		pkg     = fs.String("pkg", "main", "package name")
+		varName = fs.String("name", "files", "variable name")
+		doc     = fs.String("doc", "", "doc comment of the variable")
+		header  headerFlags
+	)
+	header.register(fs, false)
+	fs.Var(&includes, "include", "glob `pattern` of the files to include (repeatable)")
+	fs.Var(&excludes, "exclude", "glob `pattern` of the files and directories to exclude (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitCodeOK
+		}
+		return exitCodeErr
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitCodeErr
+	}
+	fsys, err := filterFS(os.DirFS(fs.Arg(0)), includes, excludes)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+		return exitCodeErr
+	}
+	n, err := astgen.BuildFS(fsys)
+	if err != nil {
+		fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+		return exitCodeErr
+	}
+	t := &target{pkg: *pkg, vars: []variable{{name: *varName, doc: *doc, expr: n.(ast.Expr)}}}
+	if err := header.apply(t, cli.now, nil); err != nil {
+		fmt.Fprintf(cli.errStream, "%s: %s\n", name, err)
+		return exitCodeErr
+	}
+	return cli.writeOutput(*output, t, "testing/fstest")
+}
+
+// filterFS reads the regular files in fsys matching the include patterns but
+// not matching the exclude patterns.
+func filterFS(fsys fs.FS, includes, excludes patternsFlag) (fstest.MapFS, error) {
+	m := fstest.MapFS{}
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == "." {
+			return err
+		}
+		if excludes.match(path) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || len(includes) > 0 && !includes.match(path) {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		m[path] = &fstest.MapFile{Data: data, Mode: info.Mode().Perm()}
+		return nil
+	})
+	return m, err
+}
+
+// patternsFlag is a repeatable flag of glob patterns. A pattern containing a
+// slash matches the whole path, and the others match the base name.
+type patternsFlag []string
+
+func (ps *patternsFlag) String() string {
+	return strings.Join(*ps, ",")
+}
+
+func (ps *patternsFlag) Set(s string) error {
+	if _, err := path.Match(s, ""); err != nil {
+		return err
+	}
+	*ps = append(*ps, s)
+	return nil
+}
+
+func (ps patternsFlag) match(name string) bool {
+	for _, p := range ps {
+		s := name
+		if !strings.Contains(p, "/") {
+			s = path.Base(name)
+		}
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+// headerFlags are the flags of the header block above the package clause.
+type headerFlags struct {
+	file       string
+	provenance bool
+	timestamp  bool
+}
+
+func (h *headerFlags) register(fs *flag.FlagSet, provenance bool) {
+	fs.StringVar(&h.file, "header", "", "`file` of the header text written above the package clause, like a license")
+	if provenance {
+		fs.BoolVar(&h.provenance, "provenance", false, "write the input file names and their SHA-256 hashes in the header")
+	}
+	fs.BoolVar(&h.timestamp, "timestamp", false, "write the generation time in the header")
+}
+
+// apply sets the header block of the target. The header text is written as
+// line comments unless it is already a comment.
+func (h *headerFlags) apply(t *target, now func() time.Time, inputs []input) error {
+	if h.file != "" {
+		text, err := os.ReadFile(h.file)
+		if err != nil {
+			return err
+		}
+		if t.license = strings.TrimRight(string(text), "\n") + "\n"; strings.TrimSpace(t.license) == "" {
+			t.license = ""
+		} else if !strings.HasPrefix(t.license, "//") && !strings.HasPrefix(t.license, "/*") {
+			var sb strings.Builder
+			for _, c := range astgen.DocComment(t.license).List {
+				sb.WriteString(c.Text + "\n")
+			}
+			t.license = sb.String()
+		}
+	}
+	if h.provenance {
+		for _, in := range inputs {
+			path := in.path
+			if path == "" || path == "-" {
+				path = "<stdin>"
+			} else {
+				path = filepath.ToSlash(path)
+			}
+			sum := sha256.Sum256(in.data)
+			t.provenance = append(t.provenance, "Source: "+path+" (sha256:"+hex.EncodeToString(sum[:])+")")
+		}
+	}
+	if h.timestamp {
+		if now == nil {
+			now = time.Now
+		}
+		t.provenance = append(t.provenance, timestampPrefix+now().UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+const timestampPrefix = "Generated at: "
+
+// maskTimestamp removes the generation time from the header comment above
+// the package clause, to compare the files generated at different times.
+func maskTimestamp(src []byte) []byte {
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	for i, line := range lines {
+		if bytes.HasPrefix(line, []byte("package ")) {
+			break
+		}
+		if bytes.HasPrefix(line, []byte("// "+timestampPrefix)) {
+			lines[i] = []byte("// " + timestampPrefix + "\n")
+		}
+	}
+	return bytes.Join(lines, nil)
+}
@@ -0,0 +1,15 @@
+// Command astgen generates Go source code of the values decoded from JSON,
+// XML, MessagePack or CBOR.
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit((&cli{
+		inStream:  os.Stdin,
+		outStream: os.Stdout,
+		errStream: os.Stderr,
+	}).run(os.Args[1:]))
+}
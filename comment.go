@@ -0,0 +1,125 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"maps"
+	"reflect"
+	"strings"
+)
+
+// WithCommentTag sets the key of the struct tag of the field comments built
+// by BuildCommented. The default key is comment.
+func WithCommentTag(key string) Option {
+	return func(b *builder) {
+		b.commentTag = key
+	}
+}
+
+// BuildCommented builds x along with the comments, assigning the positions
+// to the nodes in fset. The content of the struct tag of key comment, like
+// `comment:"timeout in seconds"`, is attached to the field as a trailing
+// comment, and the composite literals containing the comments are printed
+// one element per line. The result should be printed with fset.
+func BuildCommented(fset *token.FileSet, x any, opts ...Option) (*printer.CommentedNode, error) {
+	b := newBuilder(opts)
+	if b.commentTag == "" {
+		b.commentTag = "comment"
+	}
+	b.comments = make(map[ast.Node]string)
+	n, err := b.buildNode(reflect.ValueOf(x))
+	if err != nil {
+		return nil, err
+	}
+	multiline := commentedLits(n, b.comments)
+	maps.Copy(multiline, b.rowLits)
+	multiline = longLits(n, b.lineWidth, multiline)
+	groups := layoutNode(fset, n, b.comments, multiline)
+	return &printer.CommentedNode{Node: n, Comments: groups}, nil
+}
+
+// WithFieldComment makes BuildCommented call fn with the path of each struct
+// field like Config.Workers[3].Timeout and the value of the field, and attach
+// the returned text to the field as a trailing comment unless it is empty.
+// The text overrides the comment of the struct tag.
+func WithFieldComment(fn func(path string, v reflect.Value) string) Option {
+	return func(b *builder) {
+		b.commentFunc = fn
+	}
+}
+
+// fieldComment records the comment of the struct field from the struct tag
+// or the comment function.
+func (b *builder) fieldComment(n ast.Node, f reflect.StructField, v reflect.Value) {
+	if b.comments == nil {
+		return
+	}
+	if b.commentFunc != nil {
+		if s := b.commentFunc(strings.Join(b.path, ""), v); s != "" {
+			b.comments[n] = s
+			return
+		}
+	}
+	if s := f.Tag.Get(b.commentTag); s != "" {
+		b.comments[n] = s
+	}
+}
+
+// tracksPath reports whether the paths of the values are tracked, for the
+// comments by the function and the warnings of the unsupported values.
+func (b *builder) tracksPath() bool {
+	return !b.scratch && (b.commentFunc != nil && b.comments != nil || b.unsupWarn != nil)
+}
+
+// rootPath starts the path with the name of the type of the root value.
+func (b *builder) rootPath(v reflect.Value) {
+	b.path = b.path[:0]
+	if v.IsValid() && b.tracksPath() {
+		b.path = append(b.path, rootName(v.Type()))
+	}
+}
+
+// pushPath appends the path segment of the element of the value, which is
+// a struct field name, an index, or a map key, while building the comments
+// by the function.
+func (b *builder) pushPath(v reflect.Value, elem any) {
+	if !b.tracksPath() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		b.path = append(b.path, fieldSegment(elem.(string)))
+	case reflect.Map:
+		b.path = append(b.path, keySegment(elem.(reflect.Value)))
+	default:
+		b.path = append(b.path, indexSegment(elem.(int)))
+	}
+}
+
+func (b *builder) popPath() {
+	if !b.tracksPath() {
+		return
+	}
+	b.path = b.path[:len(b.path)-1]
+}
+
+// DocComment returns the comment group of the text, which can be set to the
+// doc comment of a declaration. Each line of the text is formatted as a line
+// comment.
+func DocComment(text string) *ast.CommentGroup {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	g := &ast.CommentGroup{}
+	for _, s := range strings.Split(text, "\n") {
+		if s = strings.TrimRight(s, " \t"); s != "" {
+			s = "// " + s
+		} else {
+			s = "//"
+		}
+		g.List = append(g.List, &ast.Comment{Text: s})
+	}
+	return g
+}
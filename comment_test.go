@@ -0,0 +1,131 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildCommented(t *testing.T) {
+	type Retry struct {
+		Count    int    `comment:"number of retries"`
+		Interval int    `comment:"interval in seconds"`
+		Name     string `doc:"name of the policy"`
+	}
+	type Config struct {
+		Name    string
+		Retries []Retry
+		Ptr     *Retry
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "struct",
+			src:      Retry{Count: 3, Interval: 10, Name: "default"},
+			expected: "Retry{\n\tCount:    3,  // number of retries\n\tInterval: 10, // interval in seconds\n\tName:     \"default\",\n}",
+		},
+		{
+			name:     "without comments",
+			src:      Retry{Name: "default"},
+			expected: `Retry{Name: "default"}`,
+		},
+		{
+			name:     "tag key",
+			src:      Retry{Count: 3, Name: "default"},
+			opts:     []astgen.Option{astgen.WithCommentTag("doc")},
+			expected: "Retry{\n\tCount: 3,\n\tName:  \"default\", // name of the policy\n}",
+		},
+		{
+			name: "nested",
+			src: Config{
+				Name:    "config",
+				Retries: []Retry{{Count: 1}, {Name: "none"}},
+				Ptr:     &Retry{Interval: 5},
+			},
+			expected: `Config{
+	Name: "config",
+	Retries: []Retry{
+		{
+			Count: 1, // number of retries
+		},
+		{Name: "none"},
+	},
+	Ptr: &Retry{
+		Interval: 5, // interval in seconds
+	},
+}`,
+		},
+		{
+			name: "comment function",
+			src: &Config{
+				Name:    "config",
+				Retries: []Retry{{Count: 1}, {Count: 2, Name: "twice"}},
+			},
+			opts: []astgen.Option{astgen.WithFieldComment(func(path string, v reflect.Value) string {
+				if strings.HasSuffix(path, ".Name") {
+					return path + " = " + v.String()
+				}
+				return ""
+			})},
+			expected: `&Config{
+	Name: "config", // Config.Name = config
+	Retries: []Retry{
+		{
+			Count: 1, // number of retries
+		},
+		{
+			Count: 2,       // number of retries
+			Name:  "twice", // Config.Retries[1].Name = twice
+		},
+	},
+}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			got, err := astgen.BuildCommented(fset, tc.src, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+			cfg.Fprint(&sb, fset, got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestDocComment(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected []string
+	}{
+		{"", nil},
+		{"Data is generated.\n", []string{"// Data is generated."}},
+		{"Data is generated.\n\nSee data.json. ", []string{"// Data is generated.", "//", "// See data.json."}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			var got []string
+			if g := astgen.DocComment(tc.text); g != nil {
+				for _, c := range g.List {
+					got = append(got, c.Text)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected: %q\ngot: %q", tc.expected, got)
+			}
+		})
+	}
+}
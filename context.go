@@ -0,0 +1,33 @@
+package astgen
+
+import (
+	"context"
+	"go/ast"
+	"reflect"
+)
+
+// BuildContext is like Build but aborts when the context is done, returning
+// the error of the context. This is useful for building large values.
+func BuildContext(ctx context.Context, x any, opts ...Option) (ast.Node, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b := newBuilder(opts)
+	b.ctx = ctx
+	return b.buildNode(reflect.ValueOf(x))
+}
+
+// contextCheckInterval is the number of the expressions built between the
+// checks of the context.
+const contextCheckInterval = 1024
+
+// checkContext returns the error of the context periodically.
+func (b *builder) checkContext() error {
+	if b.ctx == nil {
+		return nil
+	}
+	if b.steps++; b.steps%contextCheckInterval != 0 {
+		return nil
+	}
+	return b.ctx.Err()
+}
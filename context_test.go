@@ -0,0 +1,52 @@
+package astgen_test
+
+import (
+	"context"
+	"errors"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildContext(t *testing.T) {
+	got, err := astgen.BuildContext(context.Background(), map[string]int{"x": 1})
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	if expected := `map[string]int{"x": 1}`; sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := astgen.BuildContext(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected: %s\ngot: %v", context.Canceled, err)
+	}
+
+	// Cancel the context while building by the zero function.
+	src := make([]struct{ X int }, 100000)
+	for i := range src {
+		src[i].X = i + 1
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	var count int
+	_, err = astgen.BuildContext(ctx, src, astgen.WithZeroFunc(func(reflect.Value) bool {
+		if count++; count == len(src)/2 {
+			cancel()
+		}
+		return false
+	}))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected: %s\ngot: %v", context.Canceled, err)
+	}
+	if count == len(src) {
+		t.Errorf("should abort the build")
+	}
+}
@@ -0,0 +1,255 @@
+package astgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"math/cmplx"
+	"reflect"
+)
+
+// BuildVarDecl builds the variable declaration of name initialized with x,
+// which can be spliced into the declarations of a file. The variable of a nil
+// value is declared with the type, without the initializer.
+func BuildVarDecl(name string, x any, opts ...Option) (*ast.GenDecl, error) {
+	if !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("invalid variable name: %q", name)
+	}
+	v := reflect.ValueOf(x)
+	if !v.IsValid() {
+		return nil, errors.New("cannot declare untyped nil")
+	}
+	b := newBuilder(opts)
+	n, err := b.buildNode(v)
+	if err != nil {
+		return nil, err
+	}
+	s := &ast.ValueSpec{Names: []*ast.Ident{{Name: name}}, Values: []ast.Expr{n.(ast.Expr)}}
+	if id, ok := n.(*ast.Ident); ok && id.Name == "nil" {
+		if s.Type, err = b.buildType(v.Type()); err != nil {
+			return nil, err
+		}
+		s.Values = nil
+	}
+	return &ast.GenDecl{Tok: token.VAR, Specs: []ast.Spec{s}}, nil
+}
+
+// BuildStmts builds the statements declaring the local variable of name
+// initialized with x. The helper variables are declared by the preceding
+// statements, instead of the closure binding them.
+func BuildStmts(name string, x any, opts ...Option) ([]ast.Stmt, error) {
+	if !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("invalid variable name: %q", name)
+	}
+	b := newBuilder(opts)
+	stmts, e, _, err := b.buildStmts(reflect.ValueOf(x), name)
+	if err != nil {
+		return nil, err
+	}
+	return b.normalizeStmts(append(stmts, &ast.AssignStmt{
+		Lhs: []ast.Expr{&ast.Ident{Name: name}},
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{e},
+	})), nil
+}
+
+// BuildFuncDecl builds the declaration of the function of name returning x,
+// which returns a fresh value on each call, instead of sharing a package
+// level variable. The helper variables are declared in the body.
+func BuildFuncDecl(name string, x any, opts ...Option) (*ast.FuncDecl, error) {
+	if !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("invalid function name: %q", name)
+	}
+	b := newBuilder(opts)
+	stmts, e, t, err := b.buildStmts(reflect.ValueOf(x), name)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.FuncDecl{
+		Name: &ast.Ident{Name: name},
+		Type: &ast.FuncType{
+			Params:  &ast.FieldList{},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: t}}},
+		},
+		Body: &ast.BlockStmt{
+			List: b.normalizeStmts(append(stmts, &ast.ReturnStmt{Results: []ast.Expr{e}})),
+		},
+	}, nil
+}
+
+// buildStmts builds the expression and the type of the value, and the
+// statements declaring the helper variables.
+func (b *builder) buildStmts(v reflect.Value, names ...string) ([]ast.Stmt, ast.Expr, ast.Expr, error) {
+	if !v.IsValid() {
+		return nil, nil, nil, errors.New("cannot declare untyped nil")
+	}
+	v, err := b.sortedPairs(v)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	e, err := b.buildWithVars(v, names...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	t, err := b.buildType(v.Type())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stmts := make([]ast.Stmt, 0, len(b.vars)+1)
+	for _, bv := range b.vars {
+		stmts = append(stmts, defineStmt(bv.ident, bv.typ, bv.expr, bv.varptr))
+	}
+	return stmts, e, t, nil
+}
+
+func (b *builder) normalizeStmts(stmts []ast.Stmt) []ast.Stmt {
+	if b.normalize {
+		for _, s := range stmts {
+			Normalize(s)
+		}
+	}
+	return stmts
+}
+
+// defineStmt declares the variable of the type initialized with the value,
+// by the short variable declaration if the value is of the type. The value
+// referring to the helper variables is of the type.
+func defineStmt(name *ast.Ident, t, e ast.Expr, varptr bool) ast.Stmt {
+	if !varptr && !hasType(e, t) {
+		return &ast.DeclStmt{
+			Decl: &ast.GenDecl{
+				Tok: token.VAR,
+				Specs: []ast.Spec{
+					&ast.ValueSpec{
+						Names:  []*ast.Ident{name},
+						Type:   t,
+						Values: []ast.Expr{e},
+					},
+				},
+			},
+		}
+	}
+	return &ast.AssignStmt{
+		Lhs: []ast.Expr{name},
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{e},
+	}
+}
+
+// hasType reports whether the expression is obviously of the type, without
+// the type checking.
+func hasType(e, t ast.Expr) bool {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if t, ok := t.(*ast.Ident); ok {
+			return t.Name == map[token.Token]string{
+				token.INT: "int", token.FLOAT: "float64", token.IMAG: "complex128",
+				token.CHAR: "rune", token.STRING: "string",
+			}[e.Kind]
+		}
+	case *ast.Ident:
+		if t, ok := t.(*ast.Ident); ok {
+			return (e.Name == "true" || e.Name == "false") && t.Name == "bool"
+		}
+	case *ast.CallExpr:
+		return len(e.Args) == 1 && reflect.DeepEqual(e.Fun, t)
+	case *ast.FuncLit:
+		return reflect.DeepEqual(&ast.FuncType{
+			Params:  unnamedFields(e.Type.Params),
+			Results: unnamedFields(e.Type.Results),
+		}, t)
+	case *ast.CompositeLit:
+		return e.Type != nil && reflect.DeepEqual(e.Type, t)
+	}
+	return false
+}
+
+// unnamedFields drops the names of the parameters.
+func unnamedFields(l *ast.FieldList) *ast.FieldList {
+	if l == nil {
+		return nil
+	}
+	fields := make([]*ast.Field, 0, len(l.List))
+	for _, f := range l.List {
+		for i := 0; i < max(len(f.Names), 1); i++ {
+			fields = append(fields, &ast.Field{Type: f.Type})
+		}
+	}
+	return &ast.FieldList{Opening: l.Opening, List: fields, Closing: l.Closing}
+}
+
+// BuildConstDecl builds the constant declaration of name of x, which must be
+// a boolean, numeric or string value. The type of the constant is declared
+// unless it is the default type of the constant value.
+func BuildConstDecl(name string, x any, opts ...Option) (*ast.GenDecl, error) {
+	if !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("invalid constant name: %q", name)
+	}
+	v := reflect.ValueOf(x)
+	if !v.IsValid() || !isBasicKind(v.Kind()) {
+		return nil, fmt.Errorf("cannot declare %T as a constant", x)
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("cannot declare %v as a constant", f)
+		}
+	case reflect.Complex64, reflect.Complex128:
+		if c := v.Complex(); cmplx.IsNaN(c) || cmplx.IsInf(c) {
+			return nil, fmt.Errorf("cannot declare %v as a constant", c)
+		}
+	}
+	b := newBuilder(opts)
+	e, err := b.buildValueUncached(v)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := e.(*ast.CallExpr); ok && len(c.Args) == 1 { // drop the conversion
+		e = c.Args[0]
+	}
+	s := &ast.ValueSpec{Names: []*ast.Ident{{Name: name}}, Values: []ast.Expr{e}}
+	switch v.Type() {
+	case reflect.TypeOf(false), reflect.TypeOf(""), reflect.TypeOf(0),
+		reflect.TypeOf(0.0), reflect.TypeOf(0i):
+	default:
+		if s.Type, err = b.buildType(v.Type()); err != nil {
+			return nil, err
+		}
+	}
+	return &ast.GenDecl{Tok: token.CONST, Specs: []ast.Spec{s}}, nil
+}
+
+// WithAssign makes BuildAssign build the assignment with =, instead of the
+// short variable declaration.
+func WithAssign() Option {
+	return func(b *builder) {
+		b.assign = true
+	}
+}
+
+// BuildAssign builds the short variable declaration of lhs initialized with
+// x, or the assignment of x to lhs if configured by WithAssign, where lhs can
+// be an expression like cfg.Items[0].
+func BuildAssign(lhs string, x any, opts ...Option) (ast.Stmt, error) {
+	b := newBuilder(opts)
+	tok := token.DEFINE
+	if b.assign {
+		tok = token.ASSIGN
+	}
+	l, err := parser.ParseExpr(lhs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid left-hand side: %q", lhs)
+	}
+	if _, ok := l.(*ast.Ident); !ok && tok == token.DEFINE {
+		return nil, fmt.Errorf("invalid variable name: %q", lhs)
+	}
+	clearPositions(l)
+	e, err := b.buildNode(reflect.ValueOf(x))
+	if err != nil {
+		return nil, err
+	}
+	return &ast.AssignStmt{Lhs: []ast.Expr{l}, Tok: tok, Rhs: []ast.Expr{e.(ast.Expr)}}, nil
+}
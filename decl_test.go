@@ -0,0 +1,266 @@
+package astgen_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildVarDecl(t *testing.T) {
+	type T struct {
+		X int
+		Y *string
+	}
+	s := "foo"
+	d, err := astgen.BuildVarDecl("data", []T{{X: 1, Y: &s}})
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "package main\n\nfunc main() {}\n", 0)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	f.Decls = append([]ast.Decl{d}, f.Decls...)
+	var sb strings.Builder
+	(&printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}).Fprint(&sb, fset, f)
+	expected := `package main
+
+var data = (func(f string) []T {
+	return []T{{X: 1, Y: &f}}
+})("foo")
+
+func main() {}
+`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+
+	_, err = astgen.BuildVarDecl("func", 1)
+	if expected := `invalid variable name: "func"`; err == nil || err.Error() != expected {
+		t.Errorf("expected: %s\ngot: %v", expected, err)
+	}
+
+	d, err = astgen.BuildVarDecl("y", (*T)(nil))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	sb.Reset()
+	printer.Fprint(&sb, token.NewFileSet(), d)
+	if expected := "var y *T"; sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+
+	_, err = astgen.BuildVarDecl("y", nil)
+	if expected := "cannot declare untyped nil"; err == nil || err.Error() != expected {
+		t.Errorf("expected: %s\ngot: %v", expected, err)
+	}
+}
+
+func TestBuildStmts(t *testing.T) {
+	type name string
+	type T struct {
+		A *int
+		B **int
+		C *name
+		D *int8
+		E *int
+	}
+	i, n, j := 42, name("foo"), int8(1)
+	p := &i
+	testCases := []struct {
+		name     string
+		src      any
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "no helpers",
+			src:      T{},
+			expected: `x := T{}`,
+		},
+		{
+			name: "pointers",
+			src:  T{A: p, B: &p, C: &n, D: &j, E: p},
+			expected: `x1 := 42
+x2 := &x1
+var f name = "foo"
+i := int8(1)
+x := T{A: &x1, B: &x2, C: &f, D: &i, E: &x1}`,
+		},
+		{
+			name: "pointer helpers",
+			src:  T{A: p},
+			opts: []astgen.Option{astgen.WithPtrHelpers()},
+			expected: `ptrInt := func(v int) *int {
+	return &v
+}
+x := T{A: ptrInt(42)}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.BuildStmts("x", tc.src, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var ss []string
+			for _, s := range got {
+				var sb strings.Builder
+				printer.Fprint(&sb, token.NewFileSet(), s)
+				ss = append(ss, sb.String())
+			}
+			if got := strings.Join(ss, "\n"); got != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildConstDecl(t *testing.T) {
+	type level int
+	type name string
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+		err      string
+	}{
+		{name: "string", src: "foo", expected: `const x = "foo"`},
+		{name: "bool", src: true, expected: `const x = true`},
+		{name: "int", src: 42, expected: `const x = 42`},
+		{name: "int8", src: int8(-1), expected: `const x int8 = -1`},
+		{name: "uint64", src: uint64(1 << 63), expected: `const x uint64 = 9223372036854775808`},
+		{name: "float64", src: 2.0, expected: `const x = 2.0`},
+		{name: "float64 exponent", src: 1e21, expected: `const x = 1e+21`},
+		{name: "float32", src: float32(1.5), expected: `const x float32 = 1.5`},
+		{name: "complex128", src: 1 + 2i, expected: `const x = (1+2i)`},
+		{name: "named int", src: level(3), expected: `const x level = 3`},
+		{name: "named string", src: name("foo"), expected: `const x name = "foo"`},
+		{name: "duration", src: time.Second, expected: `const x time.Duration = time.Second`},
+		{name: "NaN", src: math.NaN(), err: "cannot declare NaN as a constant"},
+		{name: "slice", src: []int{1}, err: "cannot declare []int as a constant"},
+		{name: "nil", src: nil, err: "cannot declare <nil> as a constant"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.BuildConstDecl("x", tc.src)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildFuncDecl(t *testing.T) {
+	type T struct {
+		X *int
+		M map[string][]int
+	}
+	i := 42
+	d, err := astgen.BuildFuncDecl("newT", T{X: &i, M: map[string][]int{"a": {1}}})
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), d)
+	expected := `func newT() T {
+	x := 42
+	return T{X: &x, M: map[string][]int{"a": {1}}}
+}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+
+	_, err = astgen.BuildFuncDecl("newT", nil)
+	if expected := "cannot declare untyped nil"; err == nil || err.Error() != expected {
+		t.Errorf("expected: %s\ngot: %v", expected, err)
+	}
+}
+
+func TestBuildAssign(t *testing.T) {
+	i := 1
+	testCases := []struct {
+		name     string
+		lhs      string
+		src      any
+		opts     []astgen.Option
+		expected string
+		err      string
+	}{
+		{
+			name:     "define",
+			lhs:      "x",
+			src:      []int{1, 2},
+			expected: `x := []int{1, 2}`,
+		},
+		{
+			name: "define with helpers",
+			lhs:  "x",
+			src:  []*int{&i},
+			expected: `x := (func(x int) []*int {
+	return []*int{&x}
+})(1)`,
+		},
+		{
+			name:     "assign",
+			lhs:      "cfg.Items[0]",
+			src:      map[string]int{"a": 1},
+			opts:     []astgen.Option{astgen.WithAssign()},
+			expected: `cfg.Items[0] = map[string]int{"a": 1}`,
+		},
+		{
+			name: "define expression",
+			lhs:  "cfg.Items",
+			src:  1,
+			err:  `invalid variable name: "cfg.Items"`,
+		},
+		{
+			name: "invalid",
+			lhs:  "x +",
+			src:  1,
+			opts: []astgen.Option{astgen.WithAssign()},
+			err:  `invalid left-hand side: "x +"`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.BuildAssign(tc.lhs, tc.src, tc.opts...)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package astgen
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// WithDefaults registers the default values of the struct types, and makes
+// Build omit the struct fields equal to the fields of the default value of
+// the type. The literals of the types are the differences from the defaults,
+// so the zero fields are not omitted unless the default fields are zero. The
+// defaults can be the pointers of the structs.
+func WithDefaults(defaults ...any) Option {
+	return func(b *builder) {
+		if b.defaults == nil {
+			b.defaults = make(map[reflect.Type]reflect.Value, len(defaults))
+		}
+		for _, d := range defaults {
+			v := reflect.Indirect(reflect.ValueOf(d))
+			if v.Kind() != reflect.Struct {
+				panic(fmt.Sprintf("astgen: default value is not a struct: %T", d))
+			}
+			b.defaults[v.Type()] = v
+		}
+	}
+}
+
+// WithFieldFilter makes Build omit the struct fields for which fn returns
+// false, like the caches and the derived data. The synchronization
+// primitives like sync.Mutex and sync.WaitGroup are always omitted.
+func WithFieldFilter(fn func(reflect.StructField, reflect.Value) bool) Option {
+	return func(b *builder) {
+		b.fieldFilter = fn
+	}
+}
+
+// omitField reports whether the i-th field of the struct is omitted, because
+// it is the synchronization primitive like sync.Mutex, it is filtered out, it
+// is omitted by the struct tag, it equals to the default, or it is zero if
+// the type has no default. The struct tag like
+// `astgen:"-"` omits the field, and `astgen:",omitempty"` omits the field if
+// it is empty like encoding/json, even if it differs from the default. The
+// name in the tag is ignored, because the fields cannot be renamed.
+func (b *builder) omitField(v reflect.Value, i int) bool {
+	if syncTypes[v.Type().Field(i).Type] {
+		return true
+	}
+	if b.fieldFilter != nil && !b.fieldFilter(v.Type().Field(i), v.Field(i)) {
+		return true
+	}
+	if tag, ok := v.Type().Field(i).Tag.Lookup("astgen"); ok {
+		if tag == "-" {
+			return true
+		}
+		_, opts, _ := strings.Cut(tag, ",")
+		if slices.Contains(strings.Split(opts, ","), "omitempty") && isEmpty(v.Field(i)) {
+			return true
+		}
+	}
+	d, ok := b.defaults[v.Type()]
+	if !ok {
+		return b.isZero(v.Field(i))
+	}
+	f, g := v.Field(i), d.Field(i)
+	if !f.CanInterface() {
+		return isZero(f) && isZero(g)
+	}
+	return reflect.DeepEqual(f.Interface(), g.Interface())
+}
+
+// isEmpty reports whether the value is empty like encoding/json.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,130 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithDefaults(t *testing.T) {
+	type Server struct {
+		Host  string
+		Port  int
+		Debug bool
+		Tags  []string
+	}
+	type Config struct {
+		Name    string
+		Servers []Server
+	}
+	defaults := Server{Host: "localhost", Port: 8080, Tags: []string{"web"}}
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "default",
+			src:      defaults,
+			expected: `Server{}`,
+		},
+		{
+			name:     "overrides",
+			src:      Server{Host: "example.com", Port: 8080, Debug: true, Tags: []string{"web"}},
+			expected: `Server{Host: "example.com", Debug: true}`,
+		},
+		{
+			name:     "zero fields",
+			src:      Server{Host: "localhost"},
+			expected: `Server{Port: 0, Tags: []string{}}`,
+		},
+		{
+			name: "nested",
+			src: Config{Servers: []Server{
+				{Host: "localhost", Port: 8081, Tags: []string{"web"}},
+				{Host: "localhost", Port: 8080, Tags: []string{"api"}},
+			}},
+			expected: `Config{Servers: []Server{{Port: 8081}, {Tags: []string{"api"}}}}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, astgen.WithDefaults(&defaults))
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildWithFieldFilter(t *testing.T) {
+	type Item struct {
+		Name  string
+		Price int
+	}
+	type Cart struct {
+		mu    sync.Mutex
+		Items []Item
+		Total int
+		cache map[string]int
+	}
+	src := &Cart{Items: []Item{{"apple", 100}, {"banana", 0}}, Total: 100, cache: map[string]int{"apple": 100}}
+	got, err := astgen.Build(src, astgen.WithFieldFilter(func(f reflect.StructField, v reflect.Value) bool {
+		return f.IsExported() && f.Name != "Total"
+	}))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `&Cart{Items: []Item{{Name: "apple", Price: 100}, {Name: "banana"}}}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
+
+func TestBuildWithStructTags(t *testing.T) {
+	type T struct {
+		Name    string            `astgen:"name"`
+		Secret  string            `astgen:"-"`
+		Dash    string            `astgen:"-,"`
+		Tags    []string          `astgen:",omitempty"`
+		Attrs   map[string]string `astgen:"attrs,omitempty"`
+		Aliases []string
+	}
+	got, err := astgen.Build(T{
+		Name: "foo", Secret: "bar", Dash: "-",
+		Tags: []string{}, Attrs: map[string]string{}, Aliases: []string{},
+	})
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `T{Name: "foo", Dash: "-"}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+
+	got, err = astgen.Build(T{Name: "foo"}, astgen.WithDefaults(T{Dash: "-", Tags: []string{"x"}}))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	sb.Reset()
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected = `T{Name: "foo", Dash: ""}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
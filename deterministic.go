@@ -0,0 +1,50 @@
+package astgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// BuildDeterministic builds x twice, and returns an error describing the
+// difference if the results differ. This is useful to check that the input
+// produces reproducible code, including the order of the map entries and the
+// helper variables of the pointers. The cache is not used for the second
+// build.
+func BuildDeterministic(x any, opts ...Option) (ast.Node, error) {
+	v := reflect.ValueOf(x)
+	n, err := newBuilder(opts).buildNode(v)
+	if err != nil {
+		return nil, err
+	}
+	b := newBuilder(opts)
+	b.cache = nil
+	m, err := b.buildNode(v)
+	if err != nil {
+		return nil, err
+	}
+	if s, t := printNode(n), printNode(m); s != t {
+		return nil, &NondeterministicError{Type: v.Type(), Diff: DiffLines("first", "second", s, t)}
+	}
+	return n, nil
+}
+
+func printNode(n ast.Node) string {
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), n)
+	return sb.String()
+}
+
+// NondeterministicError is the error of BuildDeterministic when the builds
+// differ.
+type NondeterministicError struct {
+	Type reflect.Type
+	Diff string // difference in the diff format
+}
+
+func (err *NondeterministicError) Error() string {
+	return fmt.Sprintf("build of %s is not deterministic:\n%s", err.Type, err.Diff)
+}
@@ -0,0 +1,52 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildDeterministicCheck(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	testCases := []struct {
+		name     string
+		src      any
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "map",
+			src:      map[string]int{"foo": 1, "bar": 2, "baz": 3},
+			expected: `map[string]int{"bar": 2, "baz": 3, "foo": 1}`,
+		},
+		{
+			name: "map of pointers",
+			src:  map[*string]*string{ptr("foo"): ptr("bar"), ptr("baz"): ptr("qux")},
+			expected: `(func(baz, q, f, bar string) map[*string]*string {
+	return map[*string]*string{&baz: &q, &f: &bar}
+})("baz", "qux", "foo", "bar")`,
+		},
+		{
+			name:     "with cache",
+			src:      []int{1, 2, 3},
+			opts:     []astgen.Option{astgen.WithCache(astgen.NewCache(1))},
+			expected: `[]int{1, 2, 3}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.BuildDeterministic(tc.src, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package astgen
+
+import "strings"
+
+// DiffLines returns the line-based difference between s and t in the diff
+// format, with up to three common lines around the changes. The names label
+// s and t in the header lines.
+func DiffLines(name1, name2, s, t string) string {
+	xs, ys := strings.Split(s, "\n"), strings.Split(t, "\n")
+	var pre, suf int
+	for pre < len(xs) && pre < len(ys) && xs[pre] == ys[pre] {
+		pre++
+	}
+	for suf < len(xs)-pre && suf < len(ys)-pre &&
+		xs[len(xs)-1-suf] == ys[len(ys)-1-suf] {
+		suf++
+	}
+	var sb strings.Builder
+	sb.WriteString("--- " + name1 + "\n+++ " + name2 + "\n")
+	for _, x := range xs[max(pre-3, 0):pre] {
+		sb.WriteString(" " + x + "\n")
+	}
+	as, bs := xs[pre:len(xs)-suf], ys[pre:len(ys)-suf]
+	if len(as)*len(bs) > 1<<24 {
+		for _, a := range as {
+			sb.WriteString("-" + a + "\n")
+		}
+		for _, b := range bs {
+			sb.WriteString("+" + b + "\n")
+		}
+	} else {
+		// lcs[i][j] is the length of the longest common subsequence of as[i:]
+		// and bs[j:].
+		lcs := make([][]int, len(as)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(bs)+1)
+		}
+		for i := len(as) - 1; i >= 0; i-- {
+			for j := len(bs) - 1; j >= 0; j-- {
+				if as[i] == bs[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		var i, j int
+		for i < len(as) || j < len(bs) {
+			switch {
+			case i < len(as) && j < len(bs) && as[i] == bs[j]:
+				sb.WriteString(" " + as[i] + "\n")
+				i, j = i+1, j+1
+			case i < len(as) && (j == len(bs) || lcs[i+1][j] >= lcs[i][j+1]):
+				sb.WriteString("-" + as[i] + "\n")
+				i++
+			default:
+				sb.WriteString("+" + bs[j] + "\n")
+				j++
+			}
+		}
+	}
+	for _, x := range xs[len(xs)-suf : len(xs)-suf+min(suf, 3)] {
+		sb.WriteString(" " + x + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,114 @@
+package astgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WithErrorValues makes Build build the error values created by errors.New,
+// fmt.Errorf and errors.Join as the calls of the functions. The wrapped errors
+// of fmt.Errorf are the arguments of %w found in the message, and the errors
+// of the other types are built like the other values. Note that the errors
+// built by the calls are not equal to the original errors, like io.EOF.
+func WithErrorValues() Option {
+	return func(b *builder) {
+		b.errorValues = true
+	}
+}
+
+var errorRules = make(map[reflect.Type]func(*builder, error) (ast.Expr, error))
+
+func init() {
+	errorRules[reflect.TypeOf(errors.New(""))] = func(b *builder, err error) (ast.Expr, error) {
+		return b.errorCall("errors", "New", err.Error()), nil
+	}
+	errorRules[reflect.TypeOf(fmt.Errorf("%w", errors.ErrUnsupported))] = func(b *builder, err error) (ast.Expr, error) {
+		return b.buildErrorf(err, []error{errors.Unwrap(err)})
+	}
+	errorRules[reflect.TypeOf(fmt.Errorf("%w%w", errors.ErrUnsupported, errors.ErrUnsupported))] = func(b *builder, err error) (ast.Expr, error) {
+		return b.buildErrorf(err, err.(interface{ Unwrap() []error }).Unwrap())
+	}
+	errorRules[reflect.TypeOf(errors.Join(errors.ErrUnsupported))] = func(b *builder, err error) (ast.Expr, error) {
+		args, err := b.buildErrors(err.(interface{ Unwrap() []error }).Unwrap())
+		if err != nil {
+			return nil, err
+		}
+		return &ast.CallExpr{Fun: b.selector("errors", "Join"), Args: args}, nil
+	}
+	for t := range errorRules {
+		interfaceResults[t] = errorType
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// buildErrorValue builds the error value by the rule of the type, and reports
+// whether the type has the rule.
+func (b *builder) buildErrorValue(v reflect.Value) (ast.Expr, bool, error) {
+	r, ok := errorRules[v.Type()]
+	if !ok || !v.CanInterface() {
+		return nil, false, nil
+	}
+	if v.IsNil() {
+		return &ast.Ident{Name: "nil"}, true, nil
+	}
+	e, err := r(b, v.Interface().(error))
+	return e, true, err
+}
+
+// buildErrorf builds the error as the call of fmt.Errorf, finding the
+// messages of the wrapped errors in order, or as the call of errors.New if
+// the messages are not found. The nil errors are skipped, since the messages
+// of the verbs are kept in the formats.
+func (b *builder) buildErrorf(err error, errs []error) (ast.Expr, error) {
+	errs = slices.DeleteFunc(slices.Clone(errs), func(err error) bool { return err == nil })
+	if len(errs) == 0 {
+		return b.errorCall("errors", "New", err.Error()), nil
+	}
+	msg := err.Error()
+	var sb strings.Builder
+	for _, e := range errs {
+		i := strings.Index(msg, e.Error())
+		if i < 0 {
+			return b.errorCall("errors", "New", err.Error()), nil
+		}
+		sb.WriteString(strings.ReplaceAll(msg[:i], "%", "%%"))
+		sb.WriteString("%w")
+		msg = msg[i+len(e.Error()):]
+	}
+	sb.WriteString(strings.ReplaceAll(msg, "%", "%%"))
+	args, err := b.buildErrors(errs)
+	if err != nil {
+		return nil, err
+	}
+	e := b.errorCall("fmt", "Errorf", sb.String())
+	e.Args = append(e.Args, args...)
+	return e, nil
+}
+
+// buildErrors builds the errors as the arguments, keeping the defined types
+// of the errors like the elements of the empty interfaces.
+func (b *builder) buildErrors(errs []error) ([]ast.Expr, error) {
+	args := make([]ast.Expr, len(errs))
+	for i, err := range errs {
+		var x any = err
+		e, err := b.buildExpr(reflect.ValueOf(&x).Elem())
+		if err != nil {
+			return nil, err
+		}
+		args[i] = unwrapInterface(e)
+	}
+	return args, nil
+}
+
+func (b *builder) errorCall(pkgPath, name, msg string) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun:  b.selector(pkgPath, name),
+		Args: []ast.Expr{stringLit(strconv.Quote(msg))},
+	}
+}
@@ -0,0 +1,75 @@
+package astgen_test
+
+import (
+	"errors"
+	"fmt"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+type codeError int
+
+func (err codeError) Error() string { return fmt.Sprintf("code %d", int(err)) }
+
+func TestBuildWithErrorValues(t *testing.T) {
+	base := errors.New("not found")
+	testCases := []struct {
+		name     string
+		src      error
+		expected string
+	}{
+		{
+			name:     "errors.New",
+			src:      base,
+			expected: `errors.New("not found")`,
+		},
+		{
+			name:     "fmt.Errorf",
+			src:      fmt.Errorf("open %q: 100%% %w", "foo", base),
+			expected: `fmt.Errorf("open \"foo\": 100%% %w", errors.New("not found"))`,
+		},
+		{
+			name:     "fmt.Errorf with multiple errors",
+			src:      fmt.Errorf("%w (%w)", fmt.Errorf("read: %w", base), codeError(3)),
+			expected: `fmt.Errorf("%w (%w)", fmt.Errorf("read: %w", errors.New("not found")), codeError(3))`,
+		},
+		{
+			name:     "fmt.Errorf with nil error",
+			src:      fmt.Errorf("wrap: %w", nil),
+			expected: `errors.New("wrap: %!w(<nil>)")`,
+		},
+		{
+			name:     "fmt.Errorf with multiple errors including nil",
+			src:      fmt.Errorf("%w, %w", nil, base),
+			expected: `fmt.Errorf("%%!w(<nil>), %w", errors.New("not found"))`,
+		},
+		{
+			name:     "errors.Join",
+			src:      errors.Join(base, codeError(4)),
+			expected: `errors.Join(errors.New("not found"), codeError(4))`,
+		},
+		{
+			name:     "custom error",
+			src:      codeError(5),
+			expected: `error(codeError(5))`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build([]error{tc.src}, astgen.WithErrorValues())
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if expected := "[]error{" + tc.expected + "}"; sb.String() != expected {
+				t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package astgen
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// UnsupportedTypeError is the error of a value of the type which cannot be
+// built, like channels and functions.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+	Path string // path of the value like Config.Workers[3].Done
+}
+
+func (err *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("unexpected type: %s", err.Type.Kind())
+}
+
+// PathError is the error of building the value at the path, which locates
+// the value in the large values.
+type PathError struct {
+	Path string // path of the value like Config.Workers[3].Done
+	Err  error
+
+	segments []string // in the reversed order
+}
+
+func (err *PathError) Error() string {
+	return err.Path + ": " + err.Err.Error()
+}
+
+func (err *PathError) Unwrap() error {
+	return err.Err
+}
+
+// wrapPath prepends the segment to the path of the error.
+func wrapPath(err error, segment string) error {
+	var pe *PathError
+	if errors.As(err, &pe) {
+		pe.segments = append(pe.segments, segment)
+		return err
+	}
+	return &PathError{Err: err, segments: []string{segment}}
+}
+
+// withRoot sets the path of the error, starting with the name of the type of
+// the root value, which is the type name of the pointee if the value is a
+// pointer.
+func withRoot(err error, t reflect.Type) error {
+	var pe *PathError
+	if t == nil || !errors.As(err, &pe) {
+		return err
+	}
+	var sb strings.Builder
+	sb.WriteString(rootName(t))
+	for i := len(pe.segments) - 1; i >= 0; i-- {
+		sb.WriteString(pe.segments[i])
+	}
+	pe.Path, pe.segments = sb.String(), nil
+	var ute *UnsupportedTypeError
+	if errors.As(pe.Err, &ute) {
+		ute.Path = pe.Path
+	}
+	return err
+}
+
+// rootName returns the name of the root of the paths, which is the name of
+// the type, or the pointee type if it is a pointer.
+func rootName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func fieldSegment(name string) string {
+	return "." + name
+}
+
+func indexSegment(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+func keySegment(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return "[" + strconv.Quote(key.String()) + "]"
+	}
+	return fmt.Sprintf("[%v]", key)
+}
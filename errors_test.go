@@ -0,0 +1,84 @@
+package astgen_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildErrorPath(t *testing.T) {
+	type Worker struct {
+		Name string
+		Done chan struct{}
+	}
+	type Config struct {
+		Workers []*Worker
+		Hooks   map[string]any
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "top level",
+			src:      make(chan int),
+			expected: "unexpected type: chan",
+		},
+		{
+			name:     "field",
+			src:      Config{Workers: []*Worker{{Name: "a"}, {Name: "b", Done: make(chan struct{})}}},
+			expected: "Config.Workers[1].Done: unexpected type: chan",
+		},
+		{
+			name:     "map value",
+			src:      &Config{Hooks: map[string]any{"x": 1, "y": []any{func() {}}}},
+			expected: `Config.Hooks["y"][0]: unexpected type: func`,
+		},
+		{
+			name:     "array of nested map",
+			src:      [2]map[int]map[string]chan int{1: {2: {"z": nil}}},
+			expected: `[1][2]["z"]: unexpected type: chan`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := astgen.Build(tc.src)
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("expected: %s\ngot: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestBuildErrorTypes(t *testing.T) {
+	type T struct {
+		F []func()
+	}
+	_, err := astgen.Build(&T{F: []func(){nil, func() {}}})
+	var ute *astgen.UnsupportedTypeError
+	if !errors.As(err, &ute) {
+		t.Fatalf("expected UnsupportedTypeError: %v", err)
+	}
+	if ute.Type != reflect.TypeOf(func() {}) || ute.Path != "T.F[0]" {
+		t.Errorf("expected: %s %s\ngot: %s %s", reflect.TypeOf(func() {}), "T.F[0]", ute.Type, ute.Path)
+	}
+	var pe *astgen.PathError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected PathError: %v", err)
+	}
+	if pe.Path != "T.F[0]" || pe.Err != ute {
+		t.Errorf("expected: %s %v\ngot: %s %v", "T.F[0]", ute, pe.Path, pe.Err)
+	}
+
+	_, err = astgen.Build(make(chan int))
+	if !errors.As(err, &ute) || ute.Path != "" {
+		t.Errorf("expected UnsupportedTypeError without path: %v", err)
+	}
+	if errors.As(err, &pe) {
+		t.Errorf("should not be PathError: %v", err)
+	}
+}
@@ -0,0 +1,826 @@
+package astgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"math"
+	"net"
+	"net/netip"
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// Eval parses the expression in src, like the one built by Build, and stores
+// the value in the value pointed by into. The composite literals, the
+// conversions, the pointers, the closures binding the helper variables, and
+// the calls of the functions of the standard library emitted by the builtin
+// rules, like time.Date and netip.MustParseAddr, are supported. The named types in the interfaces are resolved by the types
+// reachable from the type of into.
+func Eval(src string, into any) error {
+	v := reflect.ValueOf(into)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("cannot evaluate into %T", into)
+	}
+	e, err := parser.ParseExpr(src)
+	if err != nil {
+		return err
+	}
+	ev := &evaluator{types: make(map[string]reflect.Type)}
+	ev.collectTypes(v.Type().Elem())
+	return ev.eval(e, v.Elem(), nil)
+}
+
+type evaluator struct {
+	types  map[string]reflect.Type
+	values map[string]reflect.Value // values of the expressions built by the rules
+}
+
+// binding is a variable bound by a closure, which is evaluated on the first
+// reference, so that the pointers of the variable are shared.
+type binding struct {
+	expr  ast.Expr
+	typ   reflect.Type
+	value reflect.Value
+	env   *env
+}
+
+type env struct {
+	vars   map[string]*binding
+	parent *env
+}
+
+func (en *env) lookup(name string) *binding {
+	for ; en != nil; en = en.parent {
+		if b, ok := en.vars[name]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+// collectTypes collects the named types reachable from the type, by the names
+// and the qualified names.
+func (ev *evaluator) collectTypes(t reflect.Type) {
+	if t.Name() != "" {
+		if _, ok := ev.types[t.String()]; ok {
+			return
+		}
+		ev.types[t.Name()], ev.types[t.String()] = t, t
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Ptr:
+		ev.collectTypes(t.Elem())
+	case reflect.Map:
+		ev.collectTypes(t.Key())
+		ev.collectTypes(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			ev.collectTypes(t.Field(i).Type)
+		}
+	}
+}
+
+// eval evaluates the expression and sets the value to v.
+func (ev *evaluator) eval(e ast.Expr, v reflect.Value, en *env) error {
+	if c, ok := constExpr(e); ok {
+		if t := typedConstType(e); t != nil {
+			if !t.AssignableTo(v.Type()) {
+				return fmt.Errorf("cannot use %s as %s", printNode(e), v.Type())
+			}
+			w := reflect.New(t).Elem()
+			if err := setConst(w, c); err != nil {
+				return err
+			}
+			v.Set(w)
+			return nil
+		}
+		return setConst(v, c)
+	}
+	switch e.(type) {
+	case *ast.CallExpr, *ast.StarExpr, *ast.UnaryExpr:
+		if w, ok := ev.values[printNode(e)]; ok && !isStdExpr(e) && (v.Kind() == reflect.Interface ||
+			w.Type().AssignableTo(v.Type())) {
+			return setValue(v, w)
+		}
+	}
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return ev.eval(e.X, v, en)
+	case *ast.Ident:
+		if e.Name == "nil" && en.lookup("nil") == nil {
+			switch v.Kind() {
+			case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+				v.Set(reflect.Zero(v.Type()))
+				return nil
+			}
+			return fmt.Errorf("cannot use nil as %s", v.Type())
+		}
+		b := en.lookup(e.Name)
+		if b == nil {
+			return fmt.Errorf("undefined: %s", e.Name)
+		}
+		w, err := ev.evalBinding(b, v.Type())
+		if err != nil {
+			return err
+		}
+		return setValue(v, w)
+	case *ast.CompositeLit:
+		return ev.evalCompositeLit(e, v, en)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return ev.evalAddr(e.X, v, en)
+		}
+	case *ast.CallExpr:
+		return ev.evalCall(e, v, en)
+	case *ast.SelectorExpr:
+		if w, ok := stdVar(e); ok {
+			return setValue(v, w)
+		}
+	}
+	return fmt.Errorf("unsupported expression: %s", printNode(e))
+}
+
+// evalBinding evaluates the bound variable, of the declared type or the type
+// of the first reference.
+func (ev *evaluator) evalBinding(b *binding, t reflect.Type) (reflect.Value, error) {
+	if !b.value.IsValid() {
+		if b.typ != nil {
+			t = b.typ
+		}
+		b.value = reflect.New(t).Elem()
+		if err := ev.eval(b.expr, b.value, b.env); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return b.value, nil
+}
+
+func setValue(v, w reflect.Value) error {
+	switch {
+	case w.Type().AssignableTo(v.Type()):
+		v.Set(w)
+	case w.Type().ConvertibleTo(v.Type()):
+		v.Set(w.Convert(v.Type()))
+	default:
+		return fmt.Errorf("cannot use %s as %s", w.Type(), v.Type())
+	}
+	return nil
+}
+
+func (ev *evaluator) evalCompositeLit(e *ast.CompositeLit, v reflect.Value, en *env) error {
+	switch v.Kind() {
+	case reflect.Interface:
+		if e.Type == nil {
+			return errors.New("cannot infer the type of the composite literal")
+		}
+		t, err := ev.resolveType(e.Type)
+		if err != nil {
+			return err
+		}
+		w := reflect.New(t).Elem()
+		if err := ev.evalCompositeLit(e, w, en); err != nil {
+			return err
+		}
+		return setValue(v, w)
+	case reflect.Ptr: // elided &T in the elements
+		w := reflect.New(v.Type().Elem())
+		if err := ev.evalCompositeLit(e, w.Elem(), en); err != nil {
+			return err
+		}
+		v.Set(w)
+		return nil
+	case reflect.Struct:
+		for i, elt := range e.Elts {
+			var f reflect.Value
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				k, ok := kv.Key.(*ast.Ident)
+				if !ok {
+					return fmt.Errorf("invalid field name: %s", printNode(kv.Key))
+				}
+				if f = v.FieldByName(k.Name); !f.IsValid() {
+					return fmt.Errorf("unknown field %s of %s", k.Name, v.Type())
+				}
+				elt = kv.Value
+			} else if i < v.NumField() {
+				f = v.Field(i)
+			} else {
+				return fmt.Errorf("too many values of %s", v.Type())
+			}
+			if !f.CanSet() { // unexported field
+				f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+			}
+			if err := ev.eval(elt, f, en); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Array, reflect.Slice:
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(e.Elts), len(e.Elts)))
+		} else if len(e.Elts) > v.Len() {
+			return fmt.Errorf("too many values of %s", v.Type())
+		}
+		for i, elt := range e.Elts {
+			if err := ev.eval(elt, v.Index(i), en); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(e.Elts)))
+		for _, elt := range e.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				return fmt.Errorf("missing key in map literal of %s", v.Type())
+			}
+			k, w := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+			if err := ev.eval(kv.Key, k, en); err != nil {
+				return err
+			}
+			if err := ev.eval(kv.Value, w, en); err != nil {
+				return err
+			}
+			v.SetMapIndex(k, w)
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot use composite literal as %s", v.Type())
+}
+
+// evalAddr evaluates the pointer of the composite literal or the variable.
+func (ev *evaluator) evalAddr(e ast.Expr, v reflect.Value, en *env) error {
+	if v.Kind() == reflect.Interface {
+		t, err := ev.typeOf(e, en)
+		if err != nil {
+			return err
+		}
+		w := reflect.New(reflect.PointerTo(t)).Elem()
+		if err := ev.evalAddr(e, w, en); err != nil {
+			return err
+		}
+		return setValue(v, w)
+	}
+	if v.Kind() != reflect.Ptr {
+		return fmt.Errorf("cannot use pointer as %s", v.Type())
+	}
+	switch e := e.(type) {
+	case *ast.CompositeLit:
+		return ev.evalCompositeLit(e, v, en)
+	case *ast.Ident:
+		b := en.lookup(e.Name)
+		if b == nil {
+			return fmt.Errorf("undefined: %s", e.Name)
+		}
+		w, err := ev.evalBinding(b, v.Type().Elem())
+		if err != nil {
+			return err
+		}
+		return setValue(v, w.Addr())
+	}
+	return fmt.Errorf("cannot take the address of %s", printNode(e))
+}
+
+// evalCall evaluates the calls of the closures, the conversions, make of the
+// channels, and the functions of the standard library.
+func (ev *evaluator) evalCall(e *ast.CallExpr, v reflect.Value, en *env) error {
+	fun := e.Fun
+	for {
+		p, ok := fun.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		fun = p.X
+	}
+	if id, ok := fun.(*ast.Ident); ok {
+		if b := en.lookup(id.Name); b != nil { // helper function
+			if fn, ok := b.expr.(*ast.FuncLit); ok {
+				return ev.evalFuncLit(fn, e.Args, v, en, b.env)
+			}
+		}
+	}
+	if fn, ok := fun.(*ast.FuncLit); ok {
+		return ev.evalFuncLit(fn, e.Args, v, en, en)
+	}
+	if id, ok := fun.(*ast.Ident); ok && id.Name == "make" && en.lookup("make") == nil {
+		return ev.evalMake(e, v)
+	}
+	if f, ok := stdFunc(fun); ok {
+		return ev.evalStdFunc(f, e, v, en)
+	}
+	if id, ok := fun.(*ast.Ident); ok && id.Name == "complex" && en.lookup("complex") == nil {
+		return ev.evalStdFunc(complexFunc, e, v, en)
+	}
+	if len(e.Args) != 1 || e.Ellipsis.IsValid() {
+		return fmt.Errorf("unsupported call: %s", printNode(e))
+	}
+	if v.Kind() == reflect.Interface {
+		t, err := ev.resolveType(fun)
+		if err != nil {
+			return err
+		}
+		w := reflect.New(t).Elem()
+		if err := ev.evalConversion(e.Args[0], w, en); err != nil {
+			return err
+		}
+		return setValue(v, w)
+	}
+	return ev.evalConversion(e.Args[0], v, en)
+}
+
+// evalMake evaluates the call of make of the channel type with the capacity.
+func (ev *evaluator) evalMake(e *ast.CallExpr, v reflect.Value) error {
+	if len(e.Args) == 0 || len(e.Args) > 2 || e.Ellipsis.IsValid() {
+		return fmt.Errorf("unsupported call: %s", printNode(e))
+	}
+	t, err := ev.resolveType(e.Args[0])
+	if err != nil {
+		return err
+	}
+	if t.Kind() != reflect.Chan {
+		return fmt.Errorf("unsupported call: %s", printNode(e))
+	}
+	var n int64
+	if len(e.Args) == 2 {
+		c, ok := constExpr(e.Args[1])
+		if !ok {
+			return fmt.Errorf("invalid capacity: %s", printNode(e.Args[1]))
+		}
+		var exact bool
+		if n, exact = constant.Int64Val(constant.ToInt(c)); !exact || n < 0 {
+			return fmt.Errorf("invalid capacity: %s", printNode(e.Args[1]))
+		}
+	}
+	w := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, t.Elem()), int(n))
+	return setValue(v, w.Convert(t))
+}
+
+// evalConversion evaluates the operand of the conversion to the type of v.
+// The string constants are converted to the byte and rune slices.
+func (ev *evaluator) evalConversion(e ast.Expr, v reflect.Value, en *env) error {
+	if c, ok := constExpr(e); ok && c.Kind() == constant.String && v.Kind() == reflect.Slice {
+		w := reflect.ValueOf(constant.StringVal(c))
+		if !w.Type().ConvertibleTo(v.Type()) {
+			return fmt.Errorf("cannot convert %s to %s", printNode(e), v.Type())
+		}
+		v.Set(w.Convert(v.Type()))
+		return nil
+	}
+	return ev.eval(e, v, en)
+}
+
+// evalFuncLit evaluates the call of the closure, whose body is the variable
+// declarations followed by the return statement. The arguments are evaluated
+// in the environment of the caller, and the body in the environment of the
+// closure.
+func (ev *evaluator) evalFuncLit(fn *ast.FuncLit, args []ast.Expr, v reflect.Value, caller, closure *env) error {
+	en := &env{vars: make(map[string]*binding), parent: closure}
+	var i int
+	for _, f := range fn.Type.Params.List {
+		t, _ := ev.resolveType(f.Type) // resolved by the reference if unknown
+		if _, ok := f.Type.(*ast.Ellipsis); ok {
+			return errors.New("unsupported variadic function")
+		}
+		for _, name := range f.Names {
+			if i >= len(args) {
+				return errors.New("not enough arguments")
+			}
+			en.vars[name.Name] = &binding{expr: args[i], typ: t, env: caller}
+			i++
+		}
+	}
+	if i != len(args) {
+		return errors.New("too many arguments")
+	}
+	for _, s := range fn.Body.List {
+		switch s := s.(type) {
+		case *ast.AssignStmt:
+			if s.Tok != token.DEFINE || len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+				break
+			}
+			if id, ok := s.Lhs[0].(*ast.Ident); ok {
+				en.vars[id.Name] = &binding{expr: s.Rhs[0], env: en}
+				continue
+			}
+		case *ast.DeclStmt:
+			if d, ok := s.Decl.(*ast.GenDecl); ok && d.Tok == token.VAR {
+				for _, s := range d.Specs {
+					s := s.(*ast.ValueSpec)
+					var t reflect.Type
+					if s.Type != nil {
+						t, _ = ev.resolveType(s.Type)
+					}
+					for i, name := range s.Names {
+						if i < len(s.Values) {
+							en.vars[name.Name] = &binding{expr: s.Values[i], typ: t, env: en}
+						}
+					}
+				}
+				continue
+			}
+		case *ast.ReturnStmt:
+			if len(s.Results) == 1 {
+				return ev.eval(s.Results[0], v, en)
+			}
+		}
+		return fmt.Errorf("unsupported statement: %s", printNode(s))
+	}
+	return errors.New("missing return statement")
+}
+
+// stdFuncs are the functions of the standard library, which the generated
+// code calls for the values having no literal representation.
+var stdFuncs = map[string]reflect.Value{
+	"math.NaN":                reflect.ValueOf(math.NaN),
+	"math.Inf":                reflect.ValueOf(math.Inf),
+	"time.Date":               reflect.ValueOf(time.Date),
+	"time.FixedZone":          reflect.ValueOf(time.FixedZone),
+	"net.ParseIP":             reflect.ValueOf(net.ParseIP),
+	"netip.MustParseAddr":     reflect.ValueOf(netip.MustParseAddr),
+	"netip.MustParseAddrPort": reflect.ValueOf(netip.MustParseAddrPort),
+	"netip.MustParsePrefix":   reflect.ValueOf(netip.MustParsePrefix),
+}
+
+// stdVars are the variables of the standard library, which the builtin rules
+// refer to.
+var stdVars = map[string]reflect.Value{
+	"time.UTC":   reflect.ValueOf(time.UTC),
+	"time.Local": reflect.ValueOf(time.Local),
+}
+
+func stdVar(e ast.Expr) (reflect.Value, bool) {
+	if sel, ok := e.(*ast.SelectorExpr); ok {
+		if x, ok := sel.X.(*ast.Ident); ok {
+			v, ok := stdVars[x.Name+"."+sel.Sel.Name]
+			return v, ok
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// isStdExpr reports whether the expression consists of the constants, and the
+// variables and the functions of the standard library, so that the expression
+// built by the builtin rule is evaluated instead of the original value.
+func isStdExpr(e ast.Expr) bool {
+	if _, ok := constExpr(e); ok {
+		return true
+	}
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return isStdExpr(e.X)
+	case *ast.SelectorExpr:
+		_, ok := stdVar(e)
+		return ok
+	case *ast.CallExpr:
+		if _, ok := stdFunc(e.Fun); !ok {
+			return false
+		}
+		for _, arg := range e.Args {
+			if !isStdExpr(arg) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// complexFunc is the builtin complex function of the float64 parts, which
+// the generated code calls for the non-finite complex numbers.
+var complexFunc = reflect.ValueOf(func(r, i float64) complex128 { return complex(r, i) })
+
+func stdFunc(e ast.Expr) (reflect.Value, bool) {
+	if sel, ok := e.(*ast.SelectorExpr); ok {
+		if x, ok := sel.X.(*ast.Ident); ok {
+			f, ok := stdFuncs[x.Name+"."+sel.Sel.Name]
+			return f, ok
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// evalStdFunc evaluates the arguments by the parameter types, and calls the
+// function of the standard library.
+func (ev *evaluator) evalStdFunc(f reflect.Value, e *ast.CallExpr, v reflect.Value, en *env) error {
+	t := f.Type()
+	if len(e.Args) != t.NumIn() || e.Ellipsis.IsValid() {
+		return fmt.Errorf("unsupported call: %s", printNode(e))
+	}
+	args := make([]reflect.Value, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = reflect.New(t.In(i)).Elem()
+		if err := ev.eval(arg, args[i], en); err != nil {
+			return err
+		}
+	}
+	w, err := callStdFunc(f, args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", printNode(e), err)
+	}
+	return setValue(v, w)
+}
+
+// callStdFunc calls the function, recovering the panic of the functions like
+// netip.MustParseAddr.
+func callStdFunc(f reflect.Value, args []reflect.Value) (w reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return f.Call(args)[0], nil
+}
+
+// typeOf returns the type of the expression in an interface.
+func (ev *evaluator) typeOf(e ast.Expr, en *env) (reflect.Type, error) {
+	if c, ok := constExpr(e); ok {
+		if t := typedConstType(e); t != nil {
+			return t, nil
+		}
+		return constType(c), nil
+	}
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return ev.typeOf(e.X, en)
+	case *ast.CompositeLit:
+		if e.Type != nil {
+			return ev.resolveType(e.Type)
+		}
+	case *ast.CallExpr:
+		if f, ok := stdFunc(e.Fun); ok {
+			return f.Type().Out(0), nil
+		}
+		if id, ok := e.Fun.(*ast.Ident); ok && id.Name == "complex" && en.lookup("complex") == nil {
+			return complexFunc.Type().Out(0), nil
+		}
+		if len(e.Args) == 1 {
+			return ev.resolveType(e.Fun)
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			t, err := ev.typeOf(e.X, en)
+			if err != nil {
+				return nil, err
+			}
+			return reflect.PointerTo(t), nil
+		}
+	case *ast.SelectorExpr:
+		if w, ok := stdVar(e); ok {
+			return w.Type(), nil
+		}
+	case *ast.Ident:
+		if b := en.lookup(e.Name); b != nil {
+			if b.value.IsValid() {
+				return b.value.Type(), nil
+			}
+			if b.typ != nil {
+				return b.typ, nil
+			}
+			return ev.typeOf(b.expr, b.env)
+		}
+	}
+	return nil, fmt.Errorf("cannot infer the type of %s", printNode(e))
+}
+
+var builtinTypes = map[string]reflect.Type{
+	"bool": reflect.TypeOf(false), "string": reflect.TypeOf(""),
+	"int": reflect.TypeOf(int(0)), "int8": reflect.TypeOf(int8(0)),
+	"int16": reflect.TypeOf(int16(0)), "int32": reflect.TypeOf(int32(0)),
+	"int64": reflect.TypeOf(int64(0)), "uint": reflect.TypeOf(uint(0)),
+	"uint8": reflect.TypeOf(uint8(0)), "uint16": reflect.TypeOf(uint16(0)),
+	"uint32": reflect.TypeOf(uint32(0)), "uint64": reflect.TypeOf(uint64(0)),
+	"uintptr": reflect.TypeOf(uintptr(0)), "float32": reflect.TypeOf(float32(0)),
+	"float64": reflect.TypeOf(float64(0)), "complex64": reflect.TypeOf(complex64(0)),
+	"complex128": reflect.TypeOf(complex128(0)), "byte": reflect.TypeOf(byte(0)),
+	"rune": reflect.TypeOf(rune(0)), "any": reflect.TypeOf((*any)(nil)).Elem(),
+}
+
+// resolveType resolves the type expression of the builtin types, the named
+// types reachable from the type of the result, and the composite types.
+func (ev *evaluator) resolveType(e ast.Expr) (reflect.Type, error) {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return ev.resolveType(e.X)
+	case *ast.Ident:
+		if t, ok := ev.types[e.Name]; ok {
+			return t, nil
+		}
+		if t, ok := builtinTypes[e.Name]; ok {
+			return t, nil
+		}
+	case *ast.SelectorExpr:
+		if t, ok := ev.types[printNode(e)]; ok {
+			return t, nil
+		}
+	case *ast.StarExpr:
+		t, err := ev.resolveType(e.X)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.PointerTo(t), nil
+	case *ast.ArrayType:
+		t, err := ev.resolveType(e.Elt)
+		if err != nil {
+			return nil, err
+		}
+		if e.Len == nil {
+			return reflect.SliceOf(t), nil
+		}
+		c, ok := constExpr(e.Len)
+		if n, exact := constant.Int64Val(c); ok && exact {
+			return reflect.ArrayOf(int(n), t), nil
+		}
+	case *ast.MapType:
+		k, err := ev.resolveType(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		t, err := ev.resolveType(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(k, t), nil
+	case *ast.ChanType:
+		t, err := ev.resolveType(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		dir := reflect.BothDir
+		switch e.Dir {
+		case ast.SEND:
+			dir = reflect.SendDir
+		case ast.RECV:
+			dir = reflect.RecvDir
+		}
+		return reflect.ChanOf(dir, t), nil
+	case *ast.InterfaceType:
+		if len(e.Methods.List) == 0 {
+			return builtinTypes["any"], nil
+		}
+	case *ast.StructType:
+		var fs []reflect.StructField
+		for _, f := range e.Fields.List {
+			t, err := ev.resolveType(f.Type)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range f.Names {
+				if !name.IsExported() {
+					return nil, fmt.Errorf("unsupported unexported field: %s", name.Name)
+				}
+				fs = append(fs, reflect.StructField{Name: name.Name, Type: t})
+			}
+		}
+		return reflect.StructOf(fs), nil
+	}
+	return nil, fmt.Errorf("cannot resolve type %s", printNode(e))
+}
+
+// constExpr evaluates the constant expression.
+func constExpr(e ast.Expr) (constant.Value, bool) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		c := constant.MakeFromLiteral(e.Value, e.Kind, 0)
+		return c, c.Kind() != constant.Unknown
+	case *ast.Ident:
+		switch e.Name {
+		case "true", "false":
+			return constant.MakeBool(e.Name == "true"), true
+		}
+	case *ast.ParenExpr:
+		return constExpr(e.X)
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			if c, ok := typedConsts[x.Name+"."+e.Sel.Name]; ok {
+				return c.value, true
+			}
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.ADD || e.Op == token.SUB {
+			if c, ok := constExpr(e.X); ok {
+				return constant.UnaryOp(e.Op, c, 0), true
+			}
+		}
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.ADD, token.SUB, token.MUL:
+			x, ok := constExpr(e.X)
+			if !ok {
+				return nil, false
+			}
+			y, ok := constExpr(e.Y)
+			if !ok {
+				return nil, false
+			}
+			return constant.BinaryOp(x, e.Op, y), true
+		}
+	}
+	return nil, false
+}
+
+// typedConsts are the typed constants of the standard library, which the
+// builtin rules refer to.
+var typedConsts = map[string]struct {
+	value constant.Value
+	typ   reflect.Type
+}{}
+
+func init() {
+	for _, du := range durationUnits {
+		typedConsts["time."+du.name] = struct {
+			value constant.Value
+			typ   reflect.Type
+		}{constant.MakeInt64(int64(du.unit)), reflect.TypeOf(du.unit)}
+	}
+	for m := time.January; m <= time.December; m++ {
+		typedConsts["time."+m.String()] = struct {
+			value constant.Value
+			typ   reflect.Type
+		}{constant.MakeInt64(int64(m)), reflect.TypeOf(m)}
+	}
+}
+
+// typedConstType returns the type of the constant expression referring to a
+// typed constant, or nil if the expression is untyped.
+func typedConstType(e ast.Expr) reflect.Type {
+	var t reflect.Type
+	ast.Inspect(e, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && t == nil {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				if c, ok := typedConsts[x.Name+"."+sel.Sel.Name]; ok {
+					t = c.typ
+				}
+			}
+			return false
+		}
+		return t == nil
+	})
+	return t
+}
+
+// constType returns the default type of the constant.
+func constType(c constant.Value) reflect.Type {
+	switch c.Kind() {
+	case constant.Bool:
+		return builtinTypes["bool"]
+	case constant.String:
+		return builtinTypes["string"]
+	case constant.Int:
+		return builtinTypes["int"]
+	case constant.Float:
+		return builtinTypes["float64"]
+	default:
+		return builtinTypes["complex128"]
+	}
+}
+
+// setConst sets the constant value to v, checking the overflow.
+func setConst(v reflect.Value, c constant.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		if c.Kind() == constant.Bool {
+			v.SetBool(constant.BoolVal(c))
+			return nil
+		}
+	case reflect.String:
+		if c.Kind() == constant.String {
+			v.SetString(constant.StringVal(c))
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, exact := constant.Int64Val(constant.ToInt(c)); exact && !v.OverflowInt(n) {
+			v.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, exact := constant.Uint64Val(constant.ToInt(c)); exact && !v.OverflowUint(n) {
+			v.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if c := constant.ToFloat(c); c.Kind() == constant.Float {
+			f, _ := constant.Float64Val(c)
+			v.SetFloat(f)
+			return nil
+		}
+	case reflect.Complex64, reflect.Complex128:
+		if c := constant.ToComplex(c); c.Kind() == constant.Complex {
+			re, _ := constant.Float64Val(constant.Real(c))
+			im, _ := constant.Float64Val(constant.Imag(c))
+			v.SetComplex(complex(re, im))
+			return nil
+		}
+	case reflect.Interface: // default type of the constant
+		w := reflect.New(constType(c)).Elem()
+		if err := setConst(w, c); err != nil {
+			return err
+		}
+		v.Set(w)
+		return nil
+	}
+	return fmt.Errorf("cannot use %s as %s", c.ExactString(), v.Type())
+}
@@ -0,0 +1,176 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestEval(t *testing.T) {
+	type Y struct {
+		y int
+	}
+	type X struct {
+		Name  string
+		Tags  []string
+		Attrs map[string]any
+		Y     Y
+		Ptr   *Y
+		Arr   [2]float32
+		C     complex64
+		U     uint8
+	}
+	testCases := []struct {
+		name string
+		src  string
+		into any
+		want any
+		err  string
+	}{
+		{
+			name: "struct",
+			src:  `X{Name: "foo", Tags: []string{"a", "b"}, Y: Y{y: 1}, Ptr: &Y{y: -2}, Arr: [2]float32{1, 2.5}, C: complex64((1 + 2i)), U: uint8(255)}`,
+			into: new(X),
+			want: X{Name: "foo", Tags: []string{"a", "b"}, Y: Y{1}, Ptr: &Y{-2}, Arr: [2]float32{1, 2.5}, C: 1 + 2i, U: 255},
+		},
+		{
+			name: "interface",
+			src:  `X{Attrs: map[string]interface{}{"x": interface{}(1), "y": interface{}([]interface{}{1.5, "z", true, nil}), "w": interface{}(Y{y: 3}), "v": interface{}(int8(-1))}}`,
+			into: new(X),
+			want: X{Attrs: map[string]any{"x": 1, "y": []any{1.5, "z", true, nil}, "w": Y{3}, "v": int8(-1)}},
+		},
+		{
+			name: "elided pointers",
+			src:  `[]*Y{{y: 1}, nil}`,
+			into: new([]*Y),
+			want: []*Y{{1}, nil},
+		},
+		{
+			name: "overflow",
+			src:  `uint8(256)`,
+			into: new(uint8),
+			err:  "cannot use 256 as uint8",
+		},
+		{
+			name: "unknown field",
+			src:  `Y{z: 1}`,
+			into: new(Y),
+			err:  "unknown field z of astgen_test.Y",
+		},
+		{
+			name: "unresolved type",
+			src:  `[]interface{}{interface{}(Z{})}`,
+			into: new([]any),
+			err:  "cannot resolve type Z",
+		},
+		{
+			name: "string conversions",
+			src:  `[]interface{}{[]byte("foo"), []rune("é"), string("bar")}`,
+			into: new([]any),
+			want: []any{[]byte("foo"), []rune("é"), "bar"},
+		},
+		{
+			name: "make slice",
+			src:  `make([]int, 3)`,
+			into: new([]int),
+			err:  "unsupported call: make([]int, 3)",
+		},
+		{
+			name: "time",
+			src:  `[]time.Time{time.Date(2020, time.January, 2, 3, 4, 5, 6, time.UTC), time.Date(2020, time.May, 2, 3, 4, 5, 0, time.FixedZone("", 3600))}`,
+			into: new([]time.Time),
+			want: []time.Time{
+				time.Date(2020, time.January, 2, 3, 4, 5, 6, time.UTC),
+				time.Date(2020, time.May, 2, 3, 4, 5, 0, time.FixedZone("", 3600)),
+			},
+		},
+		{
+			name: "network addresses",
+			src:  `[]interface{}{netip.MustParseAddr("127.0.0.1"), netip.MustParsePrefix("10.0.0.0/8"), net.ParseIP("::1")}`,
+			into: new([]any),
+			want: []any{netip.MustParseAddr("127.0.0.1"), netip.MustParsePrefix("10.0.0.0/8"), net.ParseIP("::1")},
+		},
+		{
+			name: "invalid address",
+			src:  `netip.MustParseAddr("127.0.0")`,
+			into: new(netip.Addr),
+			err:  `netip.MustParseAddr("127.0.0"): ParseAddr("127.0.0"): IPv4 address too short`,
+		},
+		{
+			name: "unsupported call",
+			src:  `f(1, 2)`,
+			into: new(int),
+			err:  "unsupported call: f(1, 2)",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := astgen.Eval(tc.src, tc.into)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			if got := reflect.ValueOf(tc.into).Elem().Interface(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected: %#v\ngot: %#v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestEvalMakeChan(t *testing.T) {
+	var got []any
+	if err := astgen.Eval(`[]interface{}{interface{}(make(chan<- int, 3)), interface{}(make(chan (<-chan int)))}`, &got); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if c, ok := got[0].(chan<- int); !ok || cap(c) != 3 {
+		t.Errorf("expected: chan<- int of capacity 3\ngot: %#v", got[0])
+	}
+	if c, ok := got[1].(chan (<-chan int)); !ok || cap(c) != 0 {
+		t.Errorf("expected: chan (<-chan int) of capacity 0\ngot: %#v", got[1])
+	}
+}
+
+func TestEvalRoundTrip(t *testing.T) {
+	type Node struct {
+		Value int
+		Next  *Node
+	}
+	n := &Node{Value: 1}
+	n.Next = &Node{Value: 2}
+	x := 42
+	type T struct {
+		Nodes []*Node
+		P, Q  *int
+		S     map[string]struct{}
+	}
+	src := T{Nodes: []*Node{n, n.Next}, P: &x, Q: &x, S: map[string]struct{}{"a": {}}}
+	got, err := astgen.Build(src)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	var v T
+	if err := astgen.Eval(sb.String(), &v); err != nil {
+		t.Fatalf("should not return error: %s\n%s", err, sb.String())
+	}
+	if !reflect.DeepEqual(v, src) {
+		t.Errorf("expected: %#v\ngot: %#v", src, v)
+	}
+	if v.P != v.Q {
+		t.Errorf("should share the pointers: %s", sb.String())
+	}
+}
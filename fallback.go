@@ -0,0 +1,197 @@
+package astgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// WithReflectFallback makes Build emit the values which cannot be expressed as
+// literals by the runtime reconstruction code, as a last resort. The structs
+// with the unexported fields of other packages, which are determined by
+// WithCurrentPackage, are reconstructed from their memory representations
+// using the unsafe package, and the fields containing pointers are set using
+// the reflect package. The functions are emitted as the function literals
+// which panic when called. The reconstruction code is marked by a constant
+// declaration in it, and is not portable across architectures.
+func WithReflectFallback() Option {
+	return func(b *builder) {
+		b.fallback = true
+	}
+}
+
+// hasInaccessibleFields reports whether the struct type has the unexported
+// fields of other packages.
+func (b *builder) hasInaccessibleFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if b.isInaccessibleField(t.Field(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNameable reports whether the type can be referred from the current
+// package.
+func (b *builder) isNameable(t reflect.Type) bool {
+	if t.Name() != "" {
+		return !b.qualify || t.PkgPath() == "" || t.PkgPath() == b.currentPkg ||
+			ast.IsExported(t.Name())
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Ptr, reflect.Chan:
+		return b.isNameable(t.Elem())
+	case reflect.Map:
+		return b.isNameable(t.Key()) && b.isNameable(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !b.isNameable(t.Field(i).Type) {
+				return false
+			}
+		}
+		return !b.hasInaccessibleFields(t)
+	case reflect.Func:
+		for i := 0; i < t.NumIn(); i++ {
+			if !b.isNameable(t.In(i)) {
+				return false
+			}
+		}
+		for i := 0; i < t.NumOut(); i++ {
+			if !b.isNameable(t.Out(i)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+const fallbackMarker = `const _ = "astgen: reconstructed by reflection; not portable"`
+
+// buildFallbackStruct builds the struct value from its memory representation.
+// The bytes of the fields containing pointers are zeroed in the blob, and the
+// fields are set using reflection.
+func (b *builder) buildFallbackStruct(v reflect.Value) (ast.Expr, error) {
+	if v.Type().Name() == "" || !b.isNameable(v.Type()) {
+		return nil, &UnsupportedTypeError{Type: v.Type()}
+	}
+	if !v.CanAddr() {
+		w := reflect.New(v.Type()).Elem()
+		if !v.CanInterface() {
+			return nil, &UnsupportedTypeError{Type: v.Type()}
+		}
+		w.Set(v)
+		v = w
+	}
+	blob := make([]byte, v.Type().Size())
+	copy(blob, unsafe.Slice((*byte)(unsafe.Pointer(v.UnsafeAddr())), len(blob)))
+	t := b.typeName(v.Type())
+	ts := b.sprint(t)
+	var fields []ast.Expr
+	var sets string
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !hasPointers(sf.Type) {
+			continue
+		}
+		clear(blob[sf.Offset : sf.Offset+sf.Type.Size()])
+		f := v.Field(i)
+		if f.IsZero() {
+			continue
+		}
+		if !b.isNameable(sf.Type) {
+			return nil, &UnsupportedTypeError{Type: sf.Type}
+		}
+		f = reflect.NewAt(sf.Type, unsafe.Pointer(f.UnsafeAddr())).Elem()
+		e, err := b.buildExpr(f)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := e.(*ast.CompositeLit); !ok { // convert untyped constants
+			t, err := b.buildType(sf.Type)
+			if err != nil {
+				return nil, err
+			}
+			e = &ast.CallExpr{Fun: t, Args: []ast.Expr{e}}
+		}
+		fields = append(fields, e)
+		sets += fmt.Sprintf("reflect.NewAt(v.Field(%[1]d).Type(), unsafe.Pointer(v.Field(%[1]d).UnsafeAddr())).Elem().Set(reflect.ValueOf(FIELD%[1]d))\n", i)
+	}
+	blob = bytes.TrimRight(blob, "\x00")
+	src := "func() " + ts + " {\n" + fallbackMarker + "\nvar x " + ts + "\n"
+	if len(blob) > 0 {
+		src += "copy(unsafe.Slice((*byte)(unsafe.Pointer(&x)), unsafe.Sizeof(x)), " + strconv.Quote(string(blob)) + ")\n"
+	}
+	if len(fields) > 0 {
+		src += "v := reflect.ValueOf(&x).Elem()\n" + sets
+		b.addImport("reflect")
+	}
+	if len(blob) > 0 || len(fields) > 0 {
+		b.addImport("unsafe")
+	}
+	src += "return x\n}()"
+	return parseFallback(src, fields), nil
+}
+
+// parseFallback parses the reconstruction code, replacing the placeholders of
+// the field values in order.
+func parseFallback(src string, fields []ast.Expr) ast.Expr {
+	e := parseTemplate(src, nil)
+	var i int
+	ast.Inspect(e, func(n ast.Node) bool {
+		if n, ok := n.(*ast.CallExpr); ok && len(n.Args) == 1 {
+			if id, ok := n.Args[0].(*ast.Ident); ok && strings.HasPrefix(id.Name, "FIELD") {
+				n.Args[0] = fields[i]
+				i++
+				return false
+			}
+		}
+		return true
+	})
+	return e
+}
+
+// buildFallbackFunc builds the function literal which panics when called,
+// converted to the type if the type is named.
+func (b *builder) buildFallbackFunc(v reflect.Value) (ast.Expr, error) {
+	if v.IsNil() {
+		return &ast.Ident{Name: "nil"}, nil
+	}
+	ft, err := b.buildTypeLit(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	body := parseTemplate(`func() { panic("astgen: function cannot be reconstructed") }`, nil).(*ast.FuncLit).Body
+	var e ast.Expr = &ast.FuncLit{Type: ft.(*ast.FuncType), Body: body}
+	t, err := b.buildType(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := t.(*ast.FuncType); !ok {
+		e = &ast.CallExpr{Fun: t, Args: []ast.Expr{e}}
+	}
+	return e, nil
+}
+
+// hasPointers reports whether the type contains pointers.
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Map, reflect.Slice, reflect.String,
+		reflect.Interface, reflect.Func, reflect.Chan:
+		return true
+	default:
+		return false
+	}
+}
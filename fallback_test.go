@@ -0,0 +1,71 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"net/http"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/itchyny/astgen-go"
+)
+
+type Secret struct {
+	id   uint16
+	name string
+	Tags []string
+}
+
+func TestBuildWithReflectFallback(t *testing.T) {
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		t.Skip("the memory representation depends on the architecture")
+	}
+	type T struct {
+		S Secret
+		P *Secret
+		F func(int, ...string) error
+		G func()
+		H http.HandlerFunc
+	}
+	got, err := astgen.Build(T{
+		S: Secret{id: 0x0101, name: "foo", Tags: []string{"bar"}},
+		P: &Secret{},
+		F: func(int, ...string) error { return nil },
+		H: func(http.ResponseWriter, *http.Request) {},
+	}, astgen.WithCurrentPackage("example.com/p"), astgen.WithReflectFallback())
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `astgen_test.T{S: func() astgen_test.Secret {
+	const _ = "astgen: reconstructed by reflection; not portable"
+	var x astgen_test.Secret
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(&x)), unsafe.Sizeof(x)), "\x01\x01")
+	v := reflect.ValueOf(&x).Elem()
+	reflect.NewAt(v.Field(1).Type(), unsafe.Pointer(v.Field(1).UnsafeAddr())).Elem().Set(reflect.ValueOf(string("foo")))
+	reflect.NewAt(v.Field(2).Type(), unsafe.Pointer(v.Field(2).UnsafeAddr())).Elem().Set(reflect.ValueOf([]string{"bar"}))
+	return x
+}(), P: &astgen_test.Secret{}, F: func(int, ...string) error {
+	panic("astgen: function cannot be reconstructed")
+}, H: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+	panic("astgen: function cannot be reconstructed")
+})}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
+
+func TestBuildWithReflectFallbackError(t *testing.T) {
+	type secret struct{ id int }
+	_, err := astgen.Build(struct{ S secret }{secret{1}},
+		astgen.WithCurrentPackage("example.com/p"), astgen.WithReflectFallback())
+	if err == nil {
+		t.Fatalf("should return error for unexported type")
+	}
+	_, err = astgen.Build(func() {})
+	if err == nil {
+		t.Fatalf("should return error for function without fallback")
+	}
+}
@@ -0,0 +1,200 @@
+package astgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"maps"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// FileBuilder builds a Go source file declaring the variables of multiple
+// values. The helper variables are declared at the package level, and shared
+// across the declarations. The helper functions, like the pointer helpers of
+// WithPtrHelpers, are declared as the functions after the variables.
+type FileBuilder struct {
+	pkg   string
+	b     *builder
+	names []string
+	exprs []ast.Expr
+	fset  *token.FileSet
+}
+
+// NewFileBuilder creates a new FileBuilder of the package name.
+func NewFileBuilder(pkg string, opts ...Option) *FileBuilder {
+	return &FileBuilder{pkg: pkg, b: newBuilder(opts)}
+}
+
+// Add builds x and adds the variable declaration of name.
+func (fb *FileBuilder) Add(name string, x any) error {
+	if !token.IsIdentifier(name) {
+		return fmt.Errorf("invalid variable name: %q", name)
+	}
+	for _, n := range fb.names {
+		if n == name {
+			return fmt.Errorf("duplicate variable name: %s", name)
+		}
+	}
+	if name == fb.b.ptrHelper {
+		return fmt.Errorf("pointer helper name %s collides with the variable name", name)
+	}
+	v, err := fb.b.sortedPairs(reflect.ValueOf(x))
+	if err != nil {
+		return err
+	}
+	fb.b.rootPath(v)
+	e, err := fb.b.buildExpr(v)
+	if err != nil {
+		return withRoot(err, v.Type())
+	}
+	if err := fb.b.checkGenericPtrHelper(e); err != nil {
+		return err
+	}
+	fb.names = append(fb.names, name)
+	fb.exprs = append(fb.exprs, e)
+	return nil
+}
+
+// File returns the file of the declarations added so far, with the imports
+// of the packages referred from the values. The positions are assigned in the
+// FileSet configured by WithFileSet or a new FileSet returned by FileSet, and
+// then the header comments configured by WithGeneratedHeader and WithGoGenerate
+// are attached to the file. Print the file with the FileSet to keep the
+// comments in place.
+func (fb *FileBuilder) File() *ast.File {
+	fb.fset = fb.b.fileSet
+	if fb.fset == nil {
+		fb.fset = token.NewFileSet()
+	}
+	return fb.file(fb.fset)
+}
+
+// FileSet returns the FileSet of the positions of the file last returned by
+// File.
+func (fb *FileBuilder) FileSet() *token.FileSet {
+	return fb.fset
+}
+
+// Source returns the source code of the file formatted like gofmt, with the
+// header comments.
+func (fb *FileBuilder) Source() ([]byte, error) {
+	fset := fb.b.fileSet
+	if fset == nil {
+		fset = token.NewFileSet()
+	}
+	var buf bytes.Buffer
+	if err := printConfig.Fprint(&buf, fset, fb.file(fset)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (fb *FileBuilder) file(fset *token.FileSet) *ast.File {
+	nodes := make([]ast.Node, len(fb.exprs))
+	for i, e := range fb.exprs {
+		nodes[i] = e
+	}
+	if len(fb.b.vars) > 0 {
+		fb.b.resolveVarNames(fb.names, nodes...)
+	}
+	f := &ast.File{Name: &ast.Ident{Name: fb.pkg}}
+	if len(fb.b.imports) > 0 {
+		paths := make([]string, 0, len(fb.b.imports))
+		for path := range fb.b.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		d := &ast.GenDecl{Tok: token.IMPORT}
+		for _, path := range paths {
+			s := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)}}
+			d.Specs = append(d.Specs, s)
+			f.Imports = append(f.Imports, s)
+		}
+		f.Decls = append(f.Decls, d)
+	}
+	var funcs []ast.Decl
+	if len(fb.b.vars) > 0 {
+		d := &ast.GenDecl{Tok: token.VAR}
+		for _, bv := range fb.b.vars {
+			if fn, ok := bv.expr.(*ast.FuncLit); ok && bv.helper {
+				funcs = append(funcs, &ast.FuncDecl{Name: bv.ident, Type: fn.Type, Body: fn.Body})
+				continue
+			}
+			s := &ast.ValueSpec{Names: []*ast.Ident{bv.ident}, Values: []ast.Expr{bv.expr}}
+			if !bv.varptr && !hasType(bv.expr, bv.typ) {
+				s.Type = bv.typ
+			}
+			d.Specs = append(d.Specs, s)
+		}
+		if len(d.Specs) > 0 {
+			f.Decls = append(f.Decls, d)
+		}
+	}
+	for i, name := range fb.names {
+		f.Decls = append(f.Decls, &ast.GenDecl{
+			Tok: token.VAR,
+			Specs: []ast.Spec{
+				&ast.ValueSpec{
+					Names:  []*ast.Ident{{Name: name}},
+					Values: []ast.Expr{fb.exprs[i]},
+				},
+			},
+		})
+	}
+	f.Decls = append(f.Decls, funcs...)
+	if d := fb.b.genericPtrHelperDecl(f); d != nil {
+		f.Decls = append(f.Decls, d)
+	}
+	if fb.b.normalize {
+		Normalize(f)
+	}
+	var header, directive *ast.CommentGroup
+	if fb.b.generatedBy != "" {
+		header = &ast.CommentGroup{List: []*ast.Comment{
+			{Text: "// Code generated by " + fb.b.generatedBy + "; DO NOT EDIT."},
+		}}
+	}
+	if fb.b.goGenerate != "" {
+		directive = &ast.CommentGroup{List: []*ast.Comment{
+			{Text: "//go:generate " + fb.b.goGenerate},
+		}}
+	}
+	f, multiline := cloneFile(f, longLits(f, fb.b.lineWidth, maps.Clone(fb.b.rowLits)))
+	layoutFile(fset, f, header, directive, multiline)
+	return f
+}
+
+// cloneFile returns a copy of the file with the composite literals in
+// multiline replaced by the copies. The layout copies the shared identifiers
+// of the helper variables, so the file is laid out in a copy to keep resolving
+// the names of the helper variables in the expressions of the builder.
+func cloneFile(f *ast.File, multiline map[*ast.CompositeLit]int) (*ast.File, map[*ast.CompositeLit]int) {
+	seen := make(map[any]reflect.Value)
+	g := cloneValue(reflect.ValueOf(f), seen).Interface().(*ast.File)
+	m := make(map[*ast.CompositeLit]int, len(multiline))
+	for lit, n := range multiline {
+		if w, ok := seen[lit]; ok {
+			m[w.Interface().(*ast.CompositeLit)] = n
+		}
+	}
+	return g, m
+}
+
+// WithGeneratedHeader makes FileBuilder write the comment of the generated
+// file by the tool, which is the standard comment recognized by the tools.
+func WithGeneratedHeader(tool string) Option {
+	return func(b *builder) {
+		b.generatedBy = tool
+	}
+}
+
+// WithGoGenerate makes FileBuilder write the go:generate directive of the
+// command, which regenerates the file.
+func WithGoGenerate(command string) Option {
+	return func(b *builder) {
+		b.goGenerate = command
+	}
+}
@@ -0,0 +1,171 @@
+package astgen_test
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestFileBuilder(t *testing.T) {
+	type T struct {
+		X *int
+		Y *string
+		N json.Number
+	}
+	i, s := 42, "foo"
+	fb := astgen.NewFileBuilder("testdata")
+	for _, d := range []struct {
+		name string
+		src  any
+	}{
+		{"first", T{X: &i, Y: &s}},
+		{"second", []*int{&i, nil}},
+		{"x", T{N: "1"}},
+	} {
+		if err := fb.Add(d.name, d.src); err != nil {
+			t.Fatalf("should not return error: %s", err)
+		}
+	}
+	if err := fb.Add("x", 1); err == nil || err.Error() != "duplicate variable name: x" {
+		t.Errorf("expected: %s\ngot: %v", "duplicate variable name: x", err)
+	}
+	var sb strings.Builder
+	if err := format.Node(&sb, token.NewFileSet(), fb.File()); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `package testdata
+
+import "encoding/json"
+
+var (
+	x1 = 42
+	f  = "foo"
+)
+var first = T{X: &x1, Y: &f}
+var second = []*int{&x1, nil}
+var x = T{N: json.Number("1")}
+`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
+
+func TestFileBuilderSource(t *testing.T) {
+	type T struct {
+		Name string
+		Tags []string
+	}
+	i := 42
+	fb := astgen.NewFileBuilder("testdata",
+		astgen.WithGeneratedHeader("astgen"),
+		astgen.WithGoGenerate("go run ./gen"),
+		astgen.WithMultiline(40),
+	)
+	for _, d := range []struct {
+		name string
+		src  any
+	}{
+		{"first", T{Name: "foo", Tags: []string{"a", "b", "c", "d", "e", "f"}}},
+		{"second", []*int{&i}},
+		{"third", json.Number("1")},
+	} {
+		if err := fb.Add(d.name, d.src); err != nil {
+			t.Fatalf("should not return error: %s", err)
+		}
+	}
+	got, err := fb.Source()
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `// Code generated by astgen; DO NOT EDIT.
+
+package testdata
+
+//go:generate go run ./gen
+
+import "encoding/json"
+
+var x = 42
+
+var first = T{
+	Name: "foo",
+	Tags: []string{"a", "b", "c", "d", "e", "f"},
+}
+
+var second = []*int{&x}
+
+var third = json.Number("1")
+`
+	if string(got) != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, got)
+	}
+	if src, err := format.Source(got); err != nil || string(src) != string(got) {
+		t.Errorf("should be formatted: %s", src)
+	}
+}
+
+func TestFileBuilderFileHeader(t *testing.T) {
+	fb := astgen.NewFileBuilder("testdata",
+		astgen.WithGeneratedHeader("astgen"),
+		astgen.WithGoGenerate("go run ./gen"),
+	)
+	if err := fb.Add("data", []int{1, 2}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	f := fb.File()
+	if !ast.IsGenerated(f) {
+		t.Errorf("should be a generated file")
+	}
+	var sb strings.Builder
+	if err := format.Node(&sb, fb.FileSet(), f); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `// Code generated by astgen; DO NOT EDIT.
+
+package testdata
+
+//go:generate go run ./gen
+
+var data = []int{1, 2}
+`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
+
+func TestFileBuilderSourceTwice(t *testing.T) {
+	i, j := 42, 43
+	fb := astgen.NewFileBuilder("testdata")
+	if err := fb.Add("first", []*int{&i, &i}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if _, err := fb.Source(); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if err := fb.Add("x", []*int{&j, &i}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	got, err := fb.Source()
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `package testdata
+
+var (
+	x1 = 42
+	x2 = 43
+)
+
+var first = []*int{&x1, &x1}
+
+var x = []*int{&x2, &x1}
+`
+	if string(got) != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, string(got))
+	}
+}
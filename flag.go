@@ -0,0 +1,46 @@
+package astgen
+
+import (
+	"flag"
+	"go/ast"
+	"reflect"
+)
+
+// FlagSet is a set of command-line flags. It is implemented by *flag.FlagSet,
+// and the flag sets of other packages (like pflag) can be adapted by visiting
+// their flags as *flag.Flag.
+type FlagSet interface {
+	VisitAll(fn func(*flag.Flag))
+}
+
+// BuildFlagDefaults builds a map literal of the default values of the flags
+// keyed by the flag names. The default values of the flags of basic types are
+// typed, and the others are the default value strings.
+func BuildFlagDefaults(fs FlagSet, opts ...Option) (ast.Node, error) {
+	m := make(map[string]any)
+	fs.VisitAll(func(f *flag.Flag) {
+		m[f.Name] = flagDefault(f)
+	})
+	return Build(m, opts...)
+}
+
+func flagDefault(f *flag.Flag) any {
+	t := reflect.TypeOf(f.Value)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return f.DefValue
+	}
+	switch t.Elem().Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		g, ok := reflect.New(t.Elem()).Interface().(flag.Getter)
+		if !ok || g.Set(f.DefValue) != nil {
+			return f.DefValue
+		}
+		if v := g.Get(); reflect.TypeOf(v).PkgPath() == "" {
+			return v
+		}
+	}
+	return f.DefValue
+}
@@ -0,0 +1,44 @@
+package astgen_test
+
+import (
+	"flag"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildFlagDefaults(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("name", "astgen", "")
+	fs.Int("count", 3, "")
+	fs.Bool("verbose", false, "")
+	fs.Float64("ratio", 0.5, "")
+	fs.Uint64("size", 1024, "")
+	fs.Duration("timeout", 5*time.Second, "")
+	fs.Func("func", "", func(string) error { return nil })
+	if err := fs.Parse([]string{"-name", "foo", "-count", "10"}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	got, err := astgen.BuildFlagDefaults(fs)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `map[string]interface {
+}{"count": interface {
+}(3), "func": interface {
+}(""), "name": interface {
+}("astgen"), "ratio": interface {
+}(0.5), "size": interface {
+}(uint64(1024)), "timeout": interface {
+}("5s"), "verbose": interface {
+}(false)}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
@@ -0,0 +1,63 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+	"io/fs"
+	"strconv"
+)
+
+// BuildFS builds a fstest.MapFS literal of the regular files in fsys, like
+//
+//	fstest.MapFS{"path/to/file": {Data: []byte("..."), Mode: 0o644}}
+//
+// so that the files can be used as an in-memory file system. The directories
+// are omitted because fstest.MapFS synthesizes the parent directories.
+func BuildFS(fsys fs.FS) (ast.Node, error) {
+	var exprs []ast.Expr
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		exprs = append(exprs, &ast.KeyValueExpr{
+			Key:   &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)},
+			Value: buildMapFile(data, info.Mode()),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ast.CompositeLit{
+		Type: &ast.SelectorExpr{
+			X:   &ast.Ident{Name: "fstest"},
+			Sel: &ast.Ident{Name: "MapFS"},
+		},
+		Elts: exprs,
+	}, nil
+}
+
+func buildMapFile(data []byte, mode fs.FileMode) ast.Expr {
+	var exprs []ast.Expr
+	if len(data) > 0 {
+		exprs = append(exprs, &ast.KeyValueExpr{
+			Key:   &ast.Ident{Name: "Data"},
+			Value: buildBytesLit(data),
+		})
+	}
+	if mode.Perm() != 0 {
+		exprs = append(exprs, &ast.KeyValueExpr{
+			Key:   &ast.Ident{Name: "Mode"},
+			Value: &ast.BasicLit{Kind: token.INT, Value: "0o" + strconv.FormatUint(uint64(mode.Perm()), 8)},
+		})
+	}
+	return &ast.CompositeLit{Elts: exprs}
+}
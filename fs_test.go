@@ -0,0 +1,31 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"README.md":        {Data: []byte("# Hello\n"), Mode: 0o644},
+		"bin/run":          {Data: []byte("#!/bin/sh\n"), Mode: 0o755},
+		"bin":              {Mode: 0o755 | 1<<31},
+		"data/empty.txt":   {},
+		"data/binary.data": {Data: []byte{0x1f, 0x8b, 0x00}, Mode: 0o600},
+	}
+	got, err := astgen.BuildFS(fsys)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `fstest.MapFS{"README.md": {Data: []byte("# Hello\n"), Mode: 0o644}, "bin/run": {Data: []byte("#!/bin/sh\n"), Mode: 0o755}, "data/binary.data": {Data: []byte("\x1f\x8b\x00"), Mode: 0o600}, "data/empty.txt": {}}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
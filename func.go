@@ -0,0 +1,97 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// WithFunc makes Build emit the function as the expression, like the name of
+// the function or the selector of a package function. The functions are
+// identified by their code pointers, so the closures created by the same
+// function literal share the expression.
+func WithFunc(fn any, e ast.Expr) Option {
+	return func(b *builder) {
+		v := reflect.ValueOf(fn)
+		if v.Kind() != reflect.Func || v.IsNil() {
+			return
+		}
+		if b.funcs == nil {
+			b.funcs = make(map[uintptr]ast.Expr)
+		}
+		b.funcs[v.Pointer()] = e
+	}
+}
+
+// WithFuncNames makes Build emit the top-level functions as their names
+// resolved by runtime.FuncForPC, qualified like the named types. The closures
+// and the method values cannot be resolved.
+func WithFuncNames() Option {
+	return func(b *builder) {
+		b.funcNames = true
+	}
+}
+
+// buildFunc builds the expression of the function registered by WithFunc or
+// resolved by WithFuncNames, and reports whether the function is found.
+func (b *builder) buildFunc(v reflect.Value) (ast.Expr, bool) {
+	if b.funcs == nil && !b.funcNames {
+		return nil, false
+	}
+	if v.IsNil() {
+		return &ast.Ident{Name: "nil"}, true
+	}
+	if e, ok := b.funcs[v.Pointer()]; ok {
+		return cloneNode(e).(ast.Expr), true
+	}
+	if !b.funcNames {
+		return nil, false
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return nil, false
+	}
+	pkgPath, name, ok := splitFuncName(f.Name())
+	if !ok {
+		return nil, false
+	}
+	if b.qualifier != nil {
+		if e := b.qualifier(pkgPath, name); e != nil {
+			if _, ok := e.(*ast.SelectorExpr); ok {
+				b.addImport(pkgPath)
+			}
+			return e, true
+		}
+	}
+	if !b.qualify || pkgPath == b.currentPkg {
+		return &ast.Ident{Name: name}, true
+	}
+	if pkgPath == "main" {
+		return nil, false
+	}
+	return b.selector(pkgPath, name), true
+}
+
+// splitFuncName splits the name of the function like net/http.NotFound to
+// the package path and the name, and reports whether the function is a
+// top-level function.
+func splitFuncName(s string) (string, string, bool) {
+	i := strings.LastIndexByte(s, '/')
+	j := strings.IndexByte(s[i+1:], '.')
+	if j < 0 {
+		return "", "", false
+	}
+	pkgPath, name := s[:i+1+j], s[i+2+j:]
+	if !token.IsIdentifier(name) {
+		return "", "", false // closures like F.func1, methods like T.M
+	}
+	// The dots in the last element of the path are escaped like yaml%2ev3.
+	pkgPath, err := url.PathUnescape(pkgPath)
+	if err != nil {
+		return "", "", false
+	}
+	return pkgPath, name, true
+}
@@ -0,0 +1,89 @@
+package astgen_test
+
+import (
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func testHandler(http.ResponseWriter, *http.Request) {}
+
+func TestBuildWithFunc(t *testing.T) {
+	type T struct {
+		Handler http.HandlerFunc
+		Upper   func(string) string
+		Hook    func()
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		opts     []astgen.Option
+		expected string
+		err      string
+	}{
+		{
+			name: "registered",
+			src:  T{Handler: testHandler, Upper: strings.ToUpper},
+			opts: []astgen.Option{
+				astgen.WithFunc(testHandler, &ast.Ident{Name: "myHandler"}),
+				astgen.WithFunc(strings.ToUpper, &ast.SelectorExpr{
+					X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "ToUpper"},
+				}),
+			},
+			expected: `T{Handler: myHandler, Upper: strings.ToUpper}`,
+		},
+		{
+			name:     "names",
+			src:      T{Handler: testHandler, Upper: strings.ToUpper},
+			opts:     []astgen.Option{astgen.WithFuncNames()},
+			expected: `T{Handler: testHandler, Upper: ToUpper}`,
+		},
+		{
+			name: "qualified names",
+			src:  []any{strings.ToUpper, http.NotFound, (func())(nil)},
+			opts: []astgen.Option{astgen.WithFuncNames(), astgen.WithCurrentPackage("example.com/p")},
+			expected: `[]interface {
+}{interface {
+}(strings.ToUpper), interface {
+}(http.NotFound), interface {
+}((func())(nil))}`,
+		},
+		{
+			name: "closure",
+			src:  T{Hook: func() {}},
+			opts: []astgen.Option{astgen.WithFuncNames()},
+			err:  "T.Hook: unexpected type: func",
+		},
+		{
+			name: "method value",
+			src:  T{Upper: strings.NewReplacer("a", "b").Replace},
+			opts: []astgen.Option{astgen.WithFuncNames()},
+			err:  "T.Upper: unexpected type: func",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, tc.opts...)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// instantiate builds the instantiated generic type of the name with the
+// type arguments in brackets, like [int,example.com/p.T], where the names of
+// the other packages are qualified by the import paths. The package paths are
+// replaced with the names, and then the arguments are parsed.
+func (b *builder) instantiate(e ast.Expr, pkgPath, pkgName, args string) ast.Expr {
+	var sb strings.Builder
+	for i := 0; i < len(args); {
+		switch j := i + 1; {
+		case args[i] == '"': // struct tag
+			for j < len(args) && args[j] != '"' {
+				if args[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			sb.WriteString(args[i:min(j+1, len(args))])
+			i = j + 1
+		case isPathByte(args[i]):
+			for j < len(args) && isPathByte(args[j]) {
+				j++
+			}
+			s := args[i:j]
+			if strings.HasPrefix(s, "...") { // variadic parameter
+				sb.WriteString("...")
+				s = s[3:]
+			}
+			if k := strings.LastIndexByte(s, '.'); k > 0 {
+				p, name := s[:k], localTypeSuffix.ReplaceAllString(s[k+1:], "")
+				n := packageName(p)
+				if p == pkgPath {
+					n = pkgName
+				}
+				s = b.sprint(b.qualifiedName(p, n, name))
+			}
+			sb.WriteString(s)
+			i = j
+		default:
+			sb.WriteByte(args[i])
+			i = j
+		}
+	}
+	x, err := parser.ParseExprFrom(token.NewFileSet(), "", "_"+sb.String(), parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	clearPositions(x)
+	switch x := x.(type) {
+	case *ast.IndexExpr:
+		return &ast.IndexExpr{X: e, Index: x.Index}
+	case *ast.IndexListExpr:
+		return &ast.IndexListExpr{X: e, Indices: x.Indices}
+	default:
+		return nil
+	}
+}
+
+func isPathByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '_' || c == '-' || c == '.' || c == '/' || c == '~' || c >= 0x80
+}
+
+// localTypeSuffix is the suffix of the names of the types declared in the
+// functions, like T·1, which is not a part of the names in the source code.
+var localTypeSuffix = regexp.MustCompile(`·\d+$`)
+
+var majorVersion = regexp.MustCompile(`^v\d+$`)
+
+// packageName guesses the package name of the path by the conventions, like
+// yaml of gopkg.in/yaml.v3, and foo of example.com/go-foo/v2.
+func packageName(pkgPath string) string {
+	name := path.Base(pkgPath)
+	if majorVersion.MatchString(name) && path.Dir(pkgPath) != "." {
+		name = path.Base(path.Dir(pkgPath))
+	}
+	name, test := strings.CutSuffix(name, "_test")
+	name, _, _ = strings.Cut(name, ".")
+	name = strings.TrimPrefix(strings.TrimSuffix(name, "-go"), "go-")
+	name = strings.Map(func(r rune) rune {
+		if r == '-' {
+			return '_'
+		}
+		return r
+	}, name)
+	if test {
+		name += "_test"
+	}
+	return name
+}
@@ -0,0 +1,91 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+type List[T any] struct {
+	Items []T
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type Entry struct {
+	Name string
+}
+
+func TestBuildGenericTypes(t *testing.T) {
+	type local struct{ X int }
+	testCases := []struct {
+		name     string
+		src      any
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "unqualified",
+			src:      List[int]{Items: []int{1, 2}},
+			expected: `List[int]{Items: []int{1, 2}}`,
+		},
+		{
+			name:     "qualified type arguments",
+			src:      Pair[string, time.Duration]{Key: "a", Value: time.Second},
+			opts:     []astgen.Option{astgen.WithCurrentPackage("example.com/p")},
+			expected: `astgen_test.Pair[string, time.Duration]{Key: "a", Value: time.Second}`,
+		},
+		{
+			name: "nested",
+			src:  &List[Pair[string, *Entry]]{Items: []Pair[string, *Entry]{{Key: "a", Value: &Entry{Name: "b"}}}},
+			opts: []astgen.Option{astgen.WithCurrentPackage("example.com/p")},
+			expected: `&astgen_test.List[astgen_test.Pair[string, *astgen_test.Entry]]{` +
+				`Items: []astgen_test.Pair[string, *astgen_test.Entry]{{Key: "a", Value: &astgen_test.Entry{Name: "b"}}}}`,
+		},
+		{
+			name:     "current package",
+			src:      List[Pair[string, *Entry]]{Items: []Pair[string, *Entry]{{Key: "a"}}},
+			opts:     []astgen.Option{astgen.WithCurrentPackage("github.com/itchyny/astgen-go_test")},
+			expected: `List[Pair[string, *Entry]]{Items: []Pair[string, *Entry]{{Key: "a"}}}`,
+		},
+		{
+			name:     "composite type arguments",
+			src:      []List[map[string][]byte]{{}},
+			opts:     []astgen.Option{astgen.WithCurrentPackage("example.com/p")},
+			expected: `[]astgen_test.List[map[string][]uint8]{{}}`,
+		},
+		{
+			name:     "func type argument",
+			src:      List[func(...time.Month) error]{},
+			opts:     []astgen.Option{astgen.WithCurrentPackage("example.com/p")},
+			expected: `astgen_test.List[func(...time.Month) error]{}`,
+		},
+		{
+			name:     "local type argument",
+			src:      List[local]{Items: []local{{X: 1}}},
+			opts:     []astgen.Option{astgen.WithCurrentPackage("example.com/p")},
+			expected: `astgen_test.List[astgen_test.local]{Items: []astgen_test.local{{X: 1}}}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package astgen
+
+import (
+	"go/ast"
+	"reflect"
+)
+
+// HelperVar is a helper variable referred from the expression built by
+// BuildWithHelpers, for the pointers, the deduplicated values and the helper
+// functions.
+type HelperVar struct {
+	Name  string
+	Type  ast.Expr
+	Value ast.Expr
+}
+
+// BuildWithHelpers builds the expression of x like Build, but returns the
+// helper variables separately instead of wrapping the expression with the
+// closure binding them. The variables are in the order of declaration; the
+// value of a variable may refer to the preceding ones. The callers can
+// declare them wherever the expression can refer to, for example as the
+// package level variables.
+func BuildWithHelpers(x any, opts ...Option) (ast.Expr, []HelperVar, error) {
+	b := newBuilder(opts)
+	v, err := b.sortedPairs(reflect.ValueOf(x))
+	if err != nil {
+		return nil, nil, err
+	}
+	e, err := b.buildWithVars(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b.normalize {
+		e = Normalize(e).(ast.Expr)
+	}
+	vars := make([]HelperVar, len(b.vars))
+	for i, bv := range b.vars {
+		vars[i] = HelperVar{Name: bv.ident.Name, Type: bv.typ, Value: bv.expr}
+		if b.normalize {
+			vars[i].Value = Normalize(bv.expr).(ast.Expr)
+		}
+	}
+	return e, vars, nil
+}
+
+// WithNamePrefix makes Build prefix the names of the helper variables, so
+// that they do not collide with the identifiers of the destination package,
+// and are easy to find in the generated code.
+func WithNamePrefix(prefix string) Option {
+	return func(b *builder) {
+		b.namePrefix = prefix
+	}
+}
+
+// NameGenerator generates the names of the helper variables.
+type NameGenerator interface {
+	// VarName returns the name of the helper variable of the value pointed,
+	// or an empty string to name it by default. The names are numbered to
+	// avoid the collisions.
+	VarName(v reflect.Value) string
+}
+
+// WithNameGenerator makes Build name the helper variables by the generator.
+func WithNameGenerator(g NameGenerator) Option {
+	return func(b *builder) {
+		b.nameGen = g
+	}
+}
@@ -0,0 +1,128 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithHelpers(t *testing.T) {
+	type T struct {
+		A *int
+		B **int
+		C *string
+		D *int
+	}
+	i, s := 42, "foo"
+	p := &i
+	testCases := []struct {
+		name     string
+		src      any
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "no helpers",
+			src:      []int{1, 2},
+			expected: `[]int{1, 2}`,
+		},
+		{
+			name: "pointers",
+			src:  T{A: p, B: &p, C: &s, D: p},
+			expected: `T{A: &x, B: &x1, C: &f, D: &x}
+var x int = 42
+var x1 *int = &x
+var f string = "foo"`,
+		},
+		{
+			name: "pointer helpers",
+			src:  T{A: p, C: &s},
+			opts: []astgen.Option{astgen.WithPtrHelpers()},
+			expected: `T{A: ptrInt(42), C: ptrString("foo")}
+var ptrInt func(int) *int = func(v int) *int {
+	return &v
+}
+var ptrString func(string) *string = func(v string) *string {
+	return &v
+}`,
+		},
+		{
+			name: "name prefix",
+			src:  T{A: p, C: &s},
+			opts: []astgen.Option{astgen.WithNamePrefix("_astgen_"), astgen.WithPtrHelpers()},
+			expected: `T{A: _astgen_ptrInt(42), C: _astgen_ptrString("foo")}
+var _astgen_ptrInt func(int) *int = func(v int) *int {
+	return &v
+}
+var _astgen_ptrString func(string) *string = func(v string) *string {
+	return &v
+}`,
+		},
+		{
+			name: "name prefix of pointers",
+			src:  T{A: p, B: &p},
+			opts: []astgen.Option{astgen.WithNamePrefix("_astgen_")},
+			expected: `T{A: &_astgen_x, B: &_astgen_x1}
+var _astgen_x int = 42
+var _astgen_x1 *int = &_astgen_x`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, vars, err := astgen.BuildWithHelpers(tc.src, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			for _, v := range vars {
+				sb.WriteString("\nvar " + v.Name + " ")
+				printer.Fprint(&sb, token.NewFileSet(), v.Type)
+				sb.WriteString(" = ")
+				printer.Fprint(&sb, token.NewFileSet(), v.Value)
+			}
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+type contentNames struct{}
+
+func (contentNames) VarName(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return strings.ToLower(v.String())
+	}
+	return ""
+}
+
+func TestBuildWithNameGenerator(t *testing.T) {
+	type T struct {
+		Owner   *string
+		Members []*string
+		Limit   *int
+	}
+	alice, bob, limit := "Alice", "Bob", 10
+	got, err := astgen.Build(T{
+		Owner:   &alice,
+		Members: []*string{&alice, &bob, new(string)},
+		Limit:   &limit,
+	}, astgen.WithNameGenerator(contentNames{}))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `(func(alice, bob, x string, x1 int) T {
+	return T{Owner: &alice, Members: []*string{&alice, &bob, &x}, Limit: &x1}
+})("Alice", "Bob", "", 10)`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
@@ -0,0 +1,33 @@
+package astgen
+
+import (
+	"go/ast"
+	"reflect"
+)
+
+// WithBeforeValue makes Build call fn with each value before building it, and
+// build the returned value instead. The hook can rewrite the values, like
+// redacting the secrets, or abort the build by returning an error.
+func WithBeforeValue(fn func(v reflect.Value) (reflect.Value, error)) Option {
+	return func(b *builder) {
+		b.beforeValue = fn
+	}
+}
+
+// WithAfterExpr makes Build call fn with each value and the built expression,
+// and use the returned expression instead. The hook can rewrite the
+// expressions, or abort the build by returning an error.
+func WithAfterExpr(fn func(v reflect.Value, e ast.Expr) (ast.Expr, error)) Option {
+	return func(b *builder) {
+		b.afterExpr = fn
+	}
+}
+
+// WithPostProcess makes Build call fn with the result, and return the node
+// returned by fn instead. The node can be rewritten in place, for example by
+// astutil.Apply. The functions of the multiple options are called in order.
+func WithPostProcess(fn func(n ast.Node) ast.Node) Option {
+	return func(b *builder) {
+		b.postProcess = append(b.postProcess, fn)
+	}
+}
@@ -0,0 +1,112 @@
+package astgen_test
+
+import (
+	"errors"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithHooks(t *testing.T) {
+	type password string
+	type T struct {
+		User     string
+		Password password
+		Ports    []int
+	}
+	src := []T{{User: "alice", Password: "secret", Ports: []int{80, 443}}}
+	testCases := []struct {
+		name     string
+		opts     []astgen.Option
+		expected string
+		err      string
+	}{
+		{
+			name: "redact",
+			opts: []astgen.Option{astgen.WithBeforeValue(func(v reflect.Value) (reflect.Value, error) {
+				if v.Type() == reflect.TypeOf(password("")) {
+					return reflect.ValueOf(password("REDACTED")), nil
+				}
+				return v, nil
+			})},
+			expected: `[]T{{User: "alice", Password: "REDACTED", Ports: []int{80, 443}}}`,
+		},
+		{
+			name: "veto",
+			opts: []astgen.Option{astgen.WithBeforeValue(func(v reflect.Value) (reflect.Value, error) {
+				if v.Type() == reflect.TypeOf(password("")) {
+					return v, errors.New("password is not allowed")
+				}
+				return v, nil
+			})},
+			err: "[0].Password: password is not allowed",
+		},
+		{
+			name: "rewrite",
+			opts: []astgen.Option{astgen.WithAfterExpr(func(v reflect.Value, e ast.Expr) (ast.Expr, error) {
+				if v.Kind() == reflect.Int {
+					return &ast.CallExpr{Fun: &ast.Ident{Name: "port"}, Args: []ast.Expr{e}}, nil
+				}
+				return e, nil
+			})},
+			expected: `[]T{{User: "alice", Password: "secret", Ports: []int{port(80), port(443)}}}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(src, tc.opts...)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildWithPostProcess(t *testing.T) {
+	type T struct {
+		Mode    int
+		Timeout float64
+	}
+	got, err := astgen.Build([]T{{Mode: 420, Timeout: 1.5}, {Mode: 493}},
+		astgen.WithPostProcess(func(n ast.Node) ast.Node {
+			ast.Inspect(n, func(n ast.Node) bool {
+				if kv, ok := n.(*ast.KeyValueExpr); ok {
+					if lit, ok := kv.Value.(*ast.BasicLit); ok && kv.Key.(*ast.Ident).Name == "Mode" {
+						kv.Value = &ast.Ident{Name: map[string]string{"420": "modeFile", "493": "modeDir"}[lit.Value]}
+					}
+				}
+				return true
+			})
+			return n
+		}),
+		astgen.WithPostProcess(func(n ast.Node) ast.Node {
+			return &ast.CallExpr{Fun: &ast.Ident{Name: "validate"}, Args: []ast.Expr{n.(ast.Expr)}}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `validate([]T{{Mode: modeFile, Timeout: 1.5}, {Mode: modeDir}})`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
@@ -0,0 +1,112 @@
+package astgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"image"
+	"reflect"
+	"strconv"
+)
+
+func init() {
+	for _, x := range []any{
+		(*image.RGBA)(nil), (*image.RGBA64)(nil),
+		(*image.NRGBA)(nil), (*image.NRGBA64)(nil),
+		(*image.Alpha)(nil), (*image.Alpha16)(nil),
+		(*image.Gray)(nil), (*image.Gray16)(nil),
+		(*image.CMYK)(nil), (*image.Paletted)(nil),
+	} {
+		builtinRules[reflect.TypeOf(x)] = (*builder).buildImage
+	}
+}
+
+// buildImage builds an image of the pixel data, like
+//
+//	&image.RGBA{Pix: []byte("..."), Stride: 4, Rect: image.Rect(0, 0, 1, 1)}
+func (b *builder) buildImage(v reflect.Value) (ast.Expr, error) {
+	if v.IsNil() {
+		return &ast.Ident{Name: "nil"}, nil
+	}
+	r := v.Elem().FieldByName("Rect").Interface().(image.Rectangle)
+	exprs := []ast.Expr{
+		&ast.KeyValueExpr{
+			Key:   &ast.Ident{Name: "Pix"},
+			Value: buildBytesLit(v.Elem().FieldByName("Pix").Bytes()),
+		},
+		&ast.KeyValueExpr{
+			Key:   &ast.Ident{Name: "Stride"},
+			Value: intLit(v.Elem().FieldByName("Stride").Int()),
+		},
+		&ast.KeyValueExpr{
+			Key: &ast.Ident{Name: "Rect"},
+			Value: &ast.CallExpr{
+				Fun: b.selector("image", "Rect"),
+				Args: []ast.Expr{
+					intLit(int64(r.Min.X)), intLit(int64(r.Min.Y)),
+					intLit(int64(r.Max.X)), intLit(int64(r.Max.Y)),
+				},
+			},
+		},
+	}
+	if p, ok := v.Interface().(*image.Paletted); ok && p.Palette != nil {
+		palette := make([]ast.Expr, len(p.Palette))
+		for i, c := range p.Palette {
+			e, err := b.buildColor(reflect.ValueOf(c))
+			if err != nil {
+				return nil, err
+			}
+			palette[i] = e
+		}
+		exprs = append(exprs, &ast.KeyValueExpr{
+			Key:   &ast.Ident{Name: "Palette"},
+			Value: &ast.CompositeLit{Type: b.selector("image/color", "Palette"), Elts: palette},
+		})
+	}
+	return &ast.UnaryExpr{
+		Op: token.AND,
+		X: &ast.CompositeLit{
+			Type: b.selector("image", v.Type().Elem().Name()),
+			Elts: exprs,
+		},
+	}, nil
+}
+
+// buildColor builds a color of the image/color package, like
+//
+//	color.RGBA{R: 255, A: 255}
+func (b *builder) buildColor(v reflect.Value) (ast.Expr, error) {
+	if v.Kind() != reflect.Struct || v.Type().PkgPath() != "image/color" {
+		return nil, fmt.Errorf("unexpected color type: %s", v.Type())
+	}
+	var exprs []ast.Expr
+	for i := 0; i < v.NumField(); i++ {
+		if isZero(v.Field(i)) {
+			continue
+		}
+		var e ast.Expr
+		switch f := v.Field(i); f.Kind() {
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			e = &ast.BasicLit{Kind: token.INT, Value: strconv.FormatUint(f.Uint(), 10)}
+		case reflect.Int8:
+			e = intLit(f.Int())
+		default: // embedded color like color.YCbCr in color.NYCbCrA
+			var err error
+			if e, err = b.buildColor(f); err != nil {
+				return nil, err
+			}
+		}
+		exprs = append(exprs, &ast.KeyValueExpr{
+			Key:   &ast.Ident{Name: v.Type().Field(i).Name},
+			Value: e,
+		})
+	}
+	return &ast.CompositeLit{
+		Type: b.selector("image/color", v.Type().Name()),
+		Elts: exprs,
+	}, nil
+}
+
+func intLit(i int64) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(i, 10)}
+}
@@ -0,0 +1,64 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildImage(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	rgba.Set(0, 0, color.RGBA{R: 255, A: 255})
+	rgba.Set(1, 0, color.RGBA{G: 128, B: 64, A: 255})
+	gray := image.NewGray(image.Rect(1, 1, 3, 2))
+	gray.SetGray(2, 1, color.Gray{Y: 0x80})
+	paletted := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{
+		color.Black, color.White, color.RGBA{R: 255, A: 255},
+		color.NYCbCrA{YCbCr: color.YCbCr{Y: 1, Cb: 2, Cr: 3}, A: 4},
+	})
+	paletted.SetColorIndex(1, 1, 2)
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "rgba",
+			src:      rgba,
+			expected: `&image.RGBA{Pix: []byte("\xff\x00\x00\xff\x00\x80@\xff"), Stride: 8, Rect: image.Rect(0, 0, 2, 1)}`,
+		},
+		{
+			name:     "gray",
+			src:      gray,
+			expected: `&image.Gray{Pix: []byte("\x00\x80"), Stride: 2, Rect: image.Rect(1, 1, 3, 2)}`,
+		},
+		{
+			name:     "paletted",
+			src:      paletted,
+			expected: `&image.Paletted{Pix: []byte("\x00\x00\x00\x02"), Stride: 2, Rect: image.Rect(0, 0, 2, 2), Palette: color.Palette{color.Gray16{}, color.Gray16{Y: 65535}, color.RGBA{R: 255, A: 255}, color.NYCbCrA{YCbCr: color.YCbCr{Y: 1, Cb: 2, Cr: 3}, A: 4}}}`,
+		},
+		{
+			name:     "nil image",
+			src:      (*image.Gray)(nil),
+			expected: `nil`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
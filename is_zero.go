@@ -1,8 +1,37 @@
 package astgen
 
-import "reflect"
+import (
+	"reflect"
+	"runtime"
+)
+
+// WithZeroFunc makes Build omit the struct fields reported as zero by fn, in
+// addition to the zero values and the values whose IsZero method returns
+// true. The IsZero method is called only when it is declared on the type of
+// the value, not promoted from an embedded field, and the pointers and the
+// interfaces are zero only when they are nil. This is useful to omit the empty values of the domain types.
+func WithZeroFunc(fn func(v reflect.Value) bool) Option {
+	return func(b *builder) {
+		b.zeroFunc = fn
+	}
+}
+
+// isZero reports whether the struct field is omitted.
+func (b *builder) isZero(val reflect.Value) bool {
+	if b.zeroFunc != nil && b.zeroFunc(val) {
+		return true
+	}
+	return isZero(val)
+}
+
+var isZeroerType = reflect.TypeOf((*interface{ IsZero() bool })(nil)).Elem()
 
 func isZero(val reflect.Value) bool {
+	if val.Kind() != reflect.Ptr && val.Kind() != reflect.Interface &&
+		val.Type().Implements(isZeroerType) && declaresMethod(val.Type(), "IsZero") &&
+		val.CanInterface() {
+		return val.Interface().(interface{ IsZero() bool }).IsZero()
+	}
 	switch val.Kind() {
 	case reflect.Bool:
 		return !val.Bool()
@@ -23,7 +52,7 @@ func isZero(val reflect.Value) bool {
 		return true
 	case reflect.Map, reflect.Slice, reflect.String:
 		return val.Len() == 0
-	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Ptr:
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Ptr, reflect.UnsafePointer:
 		return val.IsNil()
 	case reflect.Struct:
 		for i := 0; i < val.NumField(); i++ {
@@ -35,3 +64,41 @@ func isZero(val reflect.Value) bool {
 	}
 	panic("unknown type: " + val.Type().String())
 }
+
+// declaresMethod reports whether the method is declared on the type, not
+// promoted from an embedded field. The promoted methods are implemented by
+// the wrappers generated by the compiler, so the file of the method is
+// checked when an embedded field has the method.
+func declaresMethod(t reflect.Type, name string) bool {
+	m, ok := t.MethodByName(name)
+	if !ok {
+		return false
+	}
+	if t.Kind() != reflect.Struct || !hasEmbeddedMethod(t, name) {
+		return true
+	}
+	f := runtime.FuncForPC(m.Func.Pointer())
+	if f == nil {
+		return false
+	}
+	file, _ := f.FileLine(f.Entry())
+	return file != "<autogenerated>"
+}
+
+// hasEmbeddedMethod reports whether an embedded field of the struct type has
+// the method.
+func hasEmbeddedMethod(t reflect.Type, name string) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.Anonymous {
+			if _, ok := f.Type.MethodByName(name); ok {
+				return true
+			}
+			if f.Type.Kind() != reflect.Ptr {
+				if _, ok := reflect.PointerTo(f.Type).MethodByName(name); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+type money struct {
+	Amount   int
+	Currency string
+}
+
+func (m money) IsZero() bool {
+	return m.Amount == 0
+}
+
+type id string
+
+type event struct {
+	time.Time
+	Name string
+}
+
+type deadline struct {
+	time.Time
+}
+
+func (d deadline) IsZero() bool {
+	return d.Year() <= 1970
+}
+
+func TestBuildIsZero(t *testing.T) {
+	type T struct {
+		ID    id
+		Price money
+		Ptr   *money
+		Time  *time.Time
+		Event event
+		Due   deadline
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "IsZero method",
+			src:      T{ID: "x", Price: money{Currency: "USD"}, Ptr: &money{Currency: "USD"}},
+			expected: `T{ID: "x", Ptr: &money{Currency: "USD"}}`,
+		},
+		{
+			name:     "non-zero",
+			src:      T{Price: money{Amount: 100, Currency: "USD"}},
+			expected: `T{Price: money{Amount: 100, Currency: "USD"}}`,
+		},
+		{
+			name:     "pointer to zero",
+			src:      T{Time: &time.Time{}},
+			expected: `T{Time: &time.Time{}}`,
+		},
+		{
+			name:     "promoted IsZero method",
+			src:      T{Event: event{Name: "foo"}},
+			expected: `T{Event: event{Name: "foo"}}`,
+		},
+		{
+			name:     "declared IsZero method",
+			src:      T{Due: deadline{time.Unix(0, 0).UTC()}},
+			expected: `T{}`,
+		},
+		{
+			name: "zero func",
+			src:  T{ID: "none", Price: money{Amount: 100}},
+			opts: []astgen.Option{astgen.WithZeroFunc(func(v reflect.Value) bool {
+				return v.Type() == reflect.TypeOf(id("")) && v.String() == "none"
+			})},
+			expected: `T{Price: money{Amount: 100}}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestCheckIsZero(t *testing.T) {
+	type T struct {
+		Time  *time.Time
+		Event event
+	}
+	for _, src := range []any{T{Time: &time.Time{}}, T{Event: event{Name: "foo"}}} {
+		if err := astgen.Check(src); err != nil {
+			t.Errorf("should not return error: %s", err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package astgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// JSONNumberMode is the type of the decoded JSON numbers.
+type JSONNumberMode int
+
+const (
+	// JSONNumberFloat64 decodes the numbers to float64.
+	JSONNumberFloat64 JSONNumberMode = iota
+	// JSONNumberInt decodes the integers to int, and the others to float64.
+	JSONNumberInt
+	// JSONNumberInt64 decodes the integers to int64, and the others to float64.
+	JSONNumberInt64
+	// JSONNumberRaw decodes the numbers to json.Number preserving the literals.
+	JSONNumberRaw
+)
+
+// JSONBigIntMode is the handling of the JSON integers which cannot be decoded
+// exactly; the integers beyond the precision of float64 for JSONNumberFloat64,
+// and the integers overflowing int or int64 for JSONNumberInt and
+// JSONNumberInt64.
+type JSONBigIntMode int
+
+const (
+	// JSONBigIntFloat64 decodes the big integers to float64 losing precision.
+	JSONBigIntFloat64 JSONBigIntMode = iota
+	// JSONBigIntRaw decodes the big integers to json.Number.
+	JSONBigIntRaw
+	// JSONBigIntError reports an error on the big integers.
+	JSONBigIntError
+)
+
+// WithJSONNumber sets the type of the numbers decoded by BuildJSON. The
+// default mode is JSONNumberFloat64, like encoding/json.
+func WithJSONNumber(mode JSONNumberMode) Option {
+	return func(b *builder) {
+		b.jsonNumber = mode
+	}
+}
+
+// WithJSONBigInt sets the handling of the big integers decoded by BuildJSON.
+// The default mode is JSONBigIntFloat64, like encoding/json.
+func WithJSONBigInt(mode JSONBigIntMode) Option {
+	return func(b *builder) {
+		b.jsonBigInt = mode
+	}
+}
+
+// BuildJSON builds the JSON value read from r, decoded by DecodeJSON with the
+// modes configured by WithJSONNumber and WithJSONBigInt.
+func BuildJSON(r io.Reader, opts ...Option) (ast.Node, error) {
+	b := newBuilder(opts)
+	v, err := DecodeJSON(r, b.jsonNumber, b.jsonBigInt)
+	if err != nil {
+		return nil, err
+	}
+	return b.buildNode(reflect.ValueOf(v))
+}
+
+// DecodeJSON decodes the JSON value to a generic value of maps, slices,
+// strings, booleans, and the numbers of the mode.
+func DecodeJSON(r io.Reader, number JSONNumberMode, bigInt JSONBigIntMode) (any, error) {
+	d := json.NewDecoder(r)
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	offset := d.InputOffset()
+	if _, err := d.Token(); err != io.EOF {
+		return nil, fmt.Errorf("json: unexpected data after the value ending at offset %d", offset)
+	}
+	return convertJSONNumbers(v, number, bigInt)
+}
+
+func convertJSONNumbers(v any, number JSONNumberMode, bigInt JSONBigIntMode) (any, error) {
+	var err error
+	switch v := v.(type) {
+	case map[string]any:
+		for k, w := range v {
+			if v[k], err = convertJSONNumbers(w, number, bigInt); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		for i, w := range v {
+			if v[i], err = convertJSONNumbers(w, number, bigInt); err != nil {
+				return nil, err
+			}
+		}
+	case json.Number:
+		return convertJSONNumber(v, number, bigInt)
+	}
+	return v, nil
+}
+
+func convertJSONNumber(n json.Number, number JSONNumberMode, bigInt JSONBigIntMode) (any, error) {
+	if number == JSONNumberRaw {
+		return n, nil
+	}
+	s := n.String()
+	if !strings.ContainsAny(s, ".eE") { // integer
+		switch number {
+		case JSONNumberInt:
+			if i, err := strconv.ParseInt(s, 10, strconv.IntSize); err == nil {
+				return int(i), nil
+			}
+		case JSONNumberInt64:
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+		default:
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil && -1<<53 <= i && i <= 1<<53 {
+				return float64(i), nil
+			}
+		}
+		switch bigInt {
+		case JSONBigIntRaw:
+			return n, nil
+		case JSONBigIntError:
+			return nil, fmt.Errorf("json: integer cannot be decoded exactly: %s", s)
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("json: %w", err)
+	}
+	return f, nil
+}
+
+func init() {
+	builtinRules[reflect.TypeOf(json.Number(""))] = func(b *builder, v reflect.Value) (ast.Expr, error) {
+		return callExpr(token.STRING, b.selector("encoding/json", "Number"), strconv.Quote(v.String())), nil
+	}
+	builtinRules[reflect.TypeOf(json.RawMessage(nil))] = func(b *builder, v reflect.Value) (ast.Expr, error) {
+		if v.IsNil() {
+			return &ast.Ident{Name: "nil"}, nil
+		}
+		return &ast.CallExpr{
+			Fun:  b.selector("encoding/json", "RawMessage"),
+			Args: []ast.Expr{rawStringLit(string(v.Bytes()))},
+		}, nil
+	}
+}
+
+// rawStringLit builds the string literal, quoted as a raw string if the
+// string contains the characters escaped in the interpreted string, like
+// the quotes and the newlines, and can be represented as is.
+func rawStringLit(s string) *ast.BasicLit {
+	q := strconv.Quote(s)
+	if q == `"`+s+`"` || !utf8.ValidString(s) || strings.ContainsFunc(s, func(r rune) bool {
+		return r == '`' || r == '\ufeff' || r != '\t' && r != '\n' && !unicode.IsPrint(r)
+	}) {
+		return &ast.BasicLit{Kind: token.STRING, Value: q}
+	}
+	return &ast.BasicLit{Kind: token.STRING, Value: "`" + s + "`"}
+}
@@ -0,0 +1,127 @@
+package astgen_test
+
+import (
+	"encoding/json"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildJSON(t *testing.T) {
+	const src = `[1, -2.5, 1e3, 9007199254740993, 18446744073709551616]`
+	testCases := []struct {
+		name     string
+		src      string
+		opts     []astgen.Option
+		expected string
+		err      string
+	}{
+		{
+			name: "float64",
+			src:  `{"x": [1, -2.5, "foo", true, null]}`,
+			expected: `map[string]interface {
+}{"x": interface {
+}([]interface {
+}{interface {
+}(1.0), interface {
+}(-2.5), interface {
+}("foo"), interface {
+}(true), interface {
+}(nil)})}`,
+		},
+		{
+			name: "float64 with big integers",
+			src:  src,
+			expected: `[]interface {
+}{interface {
+}(1.0), interface {
+}(-2.5), interface {
+}(1000.0), interface {
+}(9.007199254740992e+15), interface {
+}(1.8446744073709552e+19)}`,
+		},
+		{
+			name: "int",
+			src:  src,
+			opts: []astgen.Option{astgen.WithJSONNumber(astgen.JSONNumberInt), astgen.WithJSONBigInt(astgen.JSONBigIntRaw)},
+			expected: `[]interface {
+}{interface {
+}(1), interface {
+}(-2.5), interface {
+}(1000.0), interface {
+}(9007199254740993), interface {
+}(json.Number("18446744073709551616"))}`,
+		},
+		{
+			name: "int64",
+			src:  `[1, 2.0]`,
+			opts: []astgen.Option{astgen.WithJSONNumber(astgen.JSONNumberInt64)},
+			expected: `[]interface {
+}{interface {
+}(int64(1)), interface {
+}(2.0)}`,
+		},
+		{
+			name: "raw",
+			src:  `{"x": 1.50}`,
+			opts: []astgen.Option{astgen.WithJSONNumber(astgen.JSONNumberRaw)},
+			expected: `map[string]interface {
+}{"x": interface {
+}(json.Number("1.50"))}`,
+		},
+		{
+			name: "big integer error",
+			src:  src,
+			opts: []astgen.Option{astgen.WithJSONBigInt(astgen.JSONBigIntError)},
+			err:  "json: integer cannot be decoded exactly: 9007199254740993",
+		},
+		{
+			name: "trailing data",
+			src:  `{} }`,
+			err:  "json: unexpected data after the value ending at offset 2",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.BuildJSON(strings.NewReader(tc.src), tc.opts...)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildJSONRawMessage(t *testing.T) {
+	type T struct {
+		Config json.RawMessage
+		Extra  []json.RawMessage
+	}
+	got, err := astgen.Build(T{
+		Config: json.RawMessage("{\n\t\"name\": \"foo\",\n\t\"path\": \"C:\\\\\"\n}"),
+		Extra:  []json.RawMessage{json.RawMessage("null"), json.RawMessage("\"`\""), nil},
+	}, astgen.WithCurrentPackage("example.com/p"))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := "astgen_test.T{Config: json.RawMessage(`{\n\t\"name\": \"foo\",\n\t\"path\": \"C:\\\\\"\n}`), " +
+		"Extra: []json.RawMessage{json.RawMessage(\"null\"), json.RawMessage(\"\\\"`\\\"\"), nil}}"
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
@@ -0,0 +1,308 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// layout assigns synthetic positions to the nodes, so that the printer breaks
+// the lines of the composite literals and places the comments.
+type layout struct {
+	base      int
+	offset    int
+	lines     []int
+	comments  map[ast.Node]string
+	multiline map[*ast.CompositeLit]int
+	groups    []*ast.CommentGroup
+	idents    map[*ast.Ident]bool
+}
+
+// layoutNode assigns the positions to the nodes in a new file of fset, and
+// returns the comment groups of the trailing comments and the doc comments.
+// The composite literals of multiline are printed in the lines of the number
+// of the elements, or one element per line.
+func layoutNode(
+	fset *token.FileSet, n ast.Node,
+	comments map[ast.Node]string, multiline map[*ast.CompositeLit]int,
+) []*ast.CommentGroup {
+	l := &layout{
+		base:      fset.Base(),
+		lines:     []int{0},
+		comments:  comments,
+		multiline: multiline,
+		idents:    make(map[*ast.Ident]bool),
+	}
+	l.node(reflect.ValueOf(n))
+	fset.AddFile("", l.base, l.offset+1).SetLines(l.lines)
+	return l.groups
+}
+
+// layoutFile assigns the positions to the nodes of the file in a new file of
+// fset, separating the declarations by blank lines. The header comment is
+// placed before the package clause, and the directive after it.
+func layoutFile(
+	fset *token.FileSet, f *ast.File,
+	header, directive *ast.CommentGroup, multiline map[*ast.CompositeLit]int,
+) {
+	l := &layout{
+		base:      fset.Base(),
+		lines:     []int{0},
+		multiline: multiline,
+		idents:    make(map[*ast.Ident]bool),
+	}
+	if header != nil {
+		l.node(reflect.ValueOf(header))
+		l.newline()
+	}
+	l.pos(&f.Package, len("package"))
+	l.node(reflect.ValueOf(f.Name))
+	if directive != nil {
+		l.newline()
+		l.newline()
+		l.node(reflect.ValueOf(directive))
+	}
+	for i := range f.Decls {
+		l.newline()
+		l.newline()
+		l.node(l.field(reflect.ValueOf(f.Decls).Index(i)))
+	}
+	fset.AddFile("", l.base, l.offset+1).SetLines(l.lines)
+	f.Comments = l.groups
+}
+
+// commentedLits returns the composite literals containing the nodes with the
+// comments, which should be printed one element per line.
+func commentedLits(n ast.Node, comments map[ast.Node]string) map[*ast.CompositeLit]int {
+	multiline := make(map[*ast.CompositeLit]int)
+	var stack []ast.Node
+	ast.Inspect(n, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		if _, ok := comments[n]; ok {
+			for _, n := range stack {
+				if n, ok := n.(*ast.CompositeLit); ok {
+					multiline[n] = 1
+				}
+			}
+		}
+		stack = append(stack, n)
+		return true
+	})
+	return multiline
+}
+
+// WithMultiline makes Source, Fprint and BuildCommented print the composite
+// literals longer than width characters in one line, one element per line.
+func WithMultiline(width int) Option {
+	return func(b *builder) {
+		b.lineWidth = width
+	}
+}
+
+// WithFileSet makes Build assign the positions to the nodes in a new file of
+// fset, so that the nodes can be merged into a file of the same FileSet, like
+// the file parsed by go/parser, and printed in the same layout as Source.
+func WithFileSet(fset *token.FileSet) Option {
+	return func(b *builder) {
+		b.fileSet = fset
+	}
+}
+
+// position assigns the positions to the nodes if configured by WithFileSet,
+// except for BuildCommented, which lays out the nodes with the comments.
+func (b *builder) position(n ast.Node) {
+	if b.fileSet != nil && b.comments == nil {
+		layoutNode(b.fileSet, n, nil, longLits(n, b.lineWidth, maps.Clone(b.rowLits)))
+	}
+}
+
+// longLits adds the composite literals longer than width to multiline, one
+// element per line unless configured. The elements of a short literal are not
+// measured, because they are shorter.
+func longLits(n ast.Node, width int, multiline map[*ast.CompositeLit]int) map[*ast.CompositeLit]int {
+	if width <= 0 {
+		return multiline
+	}
+	if multiline == nil {
+		multiline = make(map[*ast.CompositeLit]int)
+	}
+	fset := token.NewFileSet()
+	var sb strings.Builder
+	ast.Inspect(n, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok || len(lit.Elts) == 0 {
+			return true
+		}
+		sb.Reset()
+		printer.Fprint(&sb, fset, lit)
+		if len(sb.String())-strings.Count(sb.String(), "\n") <= width {
+			return false
+		}
+		if multiline[lit] == 0 {
+			multiline[lit] = 1
+		}
+		return true
+	})
+	return multiline
+}
+
+// optionalPositions are the positions of the optional tokens, which are kept
+// unset not to change the output.
+var optionalPositions = map[reflect.Type][]string{
+	reflect.TypeOf(ast.CallExpr{}):   {"Ellipsis"},
+	reflect.TypeOf(ast.ChanType{}):   {"Arrow"},
+	reflect.TypeOf(ast.GenDecl{}):    {"Lparen", "Rparen"},
+	reflect.TypeOf(ast.TypeSpec{}):   {"Assign"},
+	reflect.TypeOf(ast.ImportSpec{}): {"EndPos"},
+}
+
+var (
+	posType          = reflect.TypeOf(token.NoPos)
+	commentGroupType = reflect.TypeOf((*ast.CommentGroup)(nil))
+	nodeType         = reflect.TypeOf((*ast.Node)(nil)).Elem()
+)
+
+// node assigns the positions of the fields in the order of declaration, which
+// is the order in the source code.
+func (l *layout) node(v reflect.Value) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.IsNil() {
+		return
+	}
+	n := v.Interface().(ast.Node)
+	switch n := n.(type) {
+	case *ast.Ident:
+		l.pos(&n.NamePos, len(n.Name))
+		return
+	case *ast.BasicLit:
+		l.pos(&n.ValuePos, len(n.Value))
+		return
+	case *ast.CompositeLit:
+		if k := l.multiline[n]; k > 0 {
+			l.node(l.field(reflect.ValueOf(&n.Type).Elem()))
+			l.pos(&n.Lbrace, 1)
+			for i := range n.Elts {
+				if i%k == 0 {
+					l.newline()
+				}
+				l.node(l.field(reflect.ValueOf(n.Elts).Index(i)))
+				l.comment(n.Elts[i])
+			}
+			l.newline()
+			l.pos(&n.Rbrace, 1)
+			return
+		}
+	case *ast.BlockStmt:
+		l.pos(&n.Lbrace, 1)
+		for i := range n.List {
+			l.newline()
+			l.node(reflect.ValueOf(n.List).Index(i))
+		}
+		l.newline()
+		l.pos(&n.Rbrace, 1)
+		return
+	case *ast.GenDecl:
+		if len(n.Specs) != 1 {
+			l.genDecl(n)
+			return
+		}
+	case *ast.CommentGroup:
+		for _, c := range n.List {
+			l.pos(&c.Slash, len(c.Text))
+			l.newline()
+		}
+		l.groups = append(l.groups, n)
+		return
+	}
+	v = v.Elem()
+	optional := optionalPositions[v.Type()]
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch {
+		case f.Type() == posType:
+			if !slices.Contains(optional, v.Type().Field(i).Name) {
+				l.pos(f.Addr().Interface().(*token.Pos), 1)
+			}
+		case f.Type() == commentGroupType && v.Type().Field(i).Name == "Comment":
+			if !f.IsNil() {
+				l.trailing(f.Interface().(*ast.CommentGroup))
+			}
+		case f.Type().Implements(nodeType):
+			l.node(l.field(f))
+		case f.Kind() == reflect.Slice && f.Type().Elem().Implements(nodeType):
+			for j := 0; j < f.Len(); j++ {
+				l.node(l.field(f.Index(j)))
+			}
+		}
+	}
+}
+
+// genDecl assigns the positions of the parenthesized declaration, placing
+// one specification per line.
+func (l *layout) genDecl(n *ast.GenDecl) {
+	if n.Doc != nil {
+		l.node(reflect.ValueOf(n.Doc))
+	}
+	l.pos(&n.TokPos, len(n.Tok.String()))
+	l.pos(&n.Lparen, 1)
+	for i := range n.Specs {
+		l.newline()
+		l.node(reflect.ValueOf(n.Specs).Index(i))
+	}
+	l.newline()
+	l.pos(&n.Rparen, 1)
+}
+
+// field returns the settable field of the node, replacing the identifier
+// shared with another node by its copy, because a position is assigned to
+// each occurrence of the identifier.
+func (l *layout) field(f reflect.Value) reflect.Value {
+	if id, ok := f.Interface().(*ast.Ident); ok && id != nil && f.CanSet() {
+		if l.idents[id] {
+			id = &ast.Ident{Name: id.Name}
+			f.Set(reflect.ValueOf(id))
+		}
+		l.idents[id] = true
+	}
+	return f
+}
+
+func (l *layout) pos(p *token.Pos, width int) {
+	*p = token.Pos(l.base + l.offset)
+	l.offset += width + 1
+}
+
+func (l *layout) newline() {
+	l.lines = append(l.lines, l.offset)
+	l.offset++
+}
+
+// comment assigns the position of the trailing comment of the element, which
+// is placed after the comma.
+func (l *layout) comment(n ast.Node) {
+	if s, ok := l.comments[n]; ok {
+		l.trailing(&ast.CommentGroup{List: []*ast.Comment{{Text: commentText(s)}}})
+	}
+}
+
+func (l *layout) trailing(g *ast.CommentGroup) {
+	l.offset++
+	for _, c := range g.List {
+		l.pos(&c.Slash, len(c.Text))
+	}
+	l.groups = append(l.groups, g)
+}
+
+// commentText formats the text as a line comment.
+func commentText(s string) string {
+	return "// " + strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,49 @@
+package astgen
+
+import "fmt"
+
+// WithMaxDepth makes Build return DepthLimitError if the nesting of the
+// values exceeds the depth, counting the pointers and the interfaces. This
+// protects the generators from the untrusted or accidentally deep values.
+func WithMaxDepth(depth int) Option {
+	return func(b *builder) {
+		b.maxDepth = depth
+	}
+}
+
+// DepthLimitError is the error of the nesting exceeding the depth configured
+// by WithMaxDepth.
+type DepthLimitError struct {
+	MaxDepth int
+}
+
+func (err *DepthLimitError) Error() string {
+	return fmt.Sprintf("nesting exceeds the maximum depth %d", err.MaxDepth)
+}
+
+// WithMaxNodes makes Build return NodeLimitError if the number of the built
+// values exceeds n, before building the large slices and maps, so that the
+// accidentally large values fail fast.
+func WithMaxNodes(n int) Option {
+	return func(b *builder) {
+		b.maxNodes = n
+	}
+}
+
+// NodeLimitError is the error of the number of the values exceeding the
+// limit configured by WithMaxNodes.
+type NodeLimitError struct {
+	MaxNodes int
+}
+
+func (err *NodeLimitError) Error() string {
+	return fmt.Sprintf("number of values exceeds the maximum %d", err.MaxNodes)
+}
+
+// checkNodes returns NodeLimitError if n more values exceed the limit.
+func (b *builder) checkNodes(n int) error {
+	if b.maxNodes > 0 && b.nodes+n > b.maxNodes {
+		return &NodeLimitError{b.maxNodes}
+	}
+	return nil
+}
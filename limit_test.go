@@ -0,0 +1,78 @@
+package astgen_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithMaxDepth(t *testing.T) {
+	type list struct {
+		Next  *list
+		Value int
+	}
+	var l *list
+	for i := 0; i < 3; i++ {
+		l = &list{l, i}
+	}
+	// list{Next: &list{Next: &list{}, Value: 1}, Value: 2}
+	if _, err := astgen.Build(*l, astgen.WithMaxDepth(5)); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	_, err := astgen.Build(*l, astgen.WithMaxDepth(4))
+	if expected := "list.Next.Next: nesting exceeds the maximum depth 4"; err == nil || err.Error() != expected {
+		t.Errorf("expected: %s\ngot: %v", expected, err)
+	}
+	var dle *astgen.DepthLimitError
+	if !errors.As(err, &dle) || dle.MaxDepth != 4 {
+		t.Errorf("expected DepthLimitError: %v", err)
+	}
+}
+
+func TestBuildWithMaxNodes(t *testing.T) {
+	type T struct {
+		Name string
+		Data []byte
+		Tags map[string]int
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name: "within limit",
+			src:  T{Name: "x", Data: []byte{1, 2}, Tags: map[string]int{"a": 1}},
+		},
+		{
+			name:     "large slice",
+			src:      T{Name: "x", Data: make([]byte, 100<<20)},
+			expected: "T.Data: number of values exceeds the maximum 8",
+		},
+		{
+			name:     "large map",
+			src:      []T{{Tags: map[string]int{"a": 1, "b": 2, "c": 3}}},
+			expected: "[0].Tags: number of values exceeds the maximum 8",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := astgen.Build(tc.src, astgen.WithMaxNodes(8))
+			if tc.expected == "" {
+				if err != nil {
+					t.Fatalf("should not return error: %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expected {
+				t.Fatalf("expected: %s\ngot: %v", tc.expected, err)
+			}
+			var nle *astgen.NodeLimitError
+			if !errors.As(err, &nle) || nle.MaxNodes != 8 {
+				t.Errorf("expected NodeLimitError: %v", err)
+			}
+		})
+	}
+}
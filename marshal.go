@@ -0,0 +1,83 @@
+package astgen
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"sync"
+)
+
+// ASTMarshaler is the interface implemented by the types that build their own
+// expressions, like the calls of the constructors instead of the literals.
+// The pointer receivers are used if the values are addressable.
+type ASTMarshaler interface {
+	MarshalAST() (ast.Expr, error)
+}
+
+var astMarshalerType = reflect.TypeOf((*ASTMarshaler)(nil)).Elem()
+
+// methodKinds caches how the types implement the interfaces, because the
+// check of the method sets is costly for each value.
+var methodKinds sync.Map // map[[2]reflect.Type]methodKind
+
+type methodKind int
+
+const (
+	methodNone methodKind = iota
+	methodValue
+	methodPointer
+)
+
+func methodKindOf(t, iface reflect.Type) methodKind {
+	key := [2]reflect.Type{t, iface}
+	if k, ok := methodKinds.Load(key); ok {
+		return k.(methodKind)
+	}
+	k := methodNone
+	if t.Implements(iface) {
+		k = methodValue
+	} else if reflect.PointerTo(t).Implements(iface) {
+		k = methodPointer
+	}
+	methodKinds.Store(key, k)
+	return k
+}
+
+// methodReceiver returns the receiver of the method of the interface, and
+// reports whether the value implements the interface. The pointer receivers
+// are used if the values are addressable.
+func methodReceiver(v reflect.Value, iface reflect.Type) (reflect.Value, bool) {
+	switch methodKindOf(v.Type(), iface) {
+	case methodNone:
+		return v, false
+	case methodPointer:
+		if !v.CanAddr() {
+			return v, false
+		}
+		v = v.Addr()
+	}
+	if !v.CanInterface() {
+		return v, false
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+	}
+	return v, true
+}
+
+// marshalAST builds the expression by the MarshalAST method, and reports
+// whether the value implements ASTMarshaler.
+func marshalAST(v reflect.Value) (ast.Expr, bool, error) {
+	v, ok := methodReceiver(v, astMarshalerType)
+	if !ok {
+		return nil, false, nil
+	}
+	e, err := v.Interface().(ASTMarshaler).MarshalAST()
+	if err != nil {
+		return nil, true, fmt.Errorf("MarshalAST of %s: %w", v.Type(), err)
+	}
+	return e, true, nil
+}
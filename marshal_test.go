@@ -0,0 +1,89 @@
+package astgen_test
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+type version struct {
+	major, minor int
+}
+
+func (v version) MarshalAST() (ast.Expr, error) {
+	if v.major < 0 {
+		return nil, errors.New("invalid version")
+	}
+	return parser.ParseExpr(fmt.Sprintf("semver.New(%d, %d)", v.major, v.minor))
+}
+
+type label struct {
+	name string
+}
+
+func (l *label) MarshalAST() (ast.Expr, error) {
+	return parser.ParseExpr(fmt.Sprintf("newLabel(%q)", l.name))
+}
+
+func TestBuildASTMarshaler(t *testing.T) {
+	type T struct {
+		V  version
+		P  *version
+		L  []label
+		LP *label
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+		err      string
+	}{
+		{
+			name:     "value receiver",
+			src:      version{1, 2},
+			expected: `semver.New(1, 2)`,
+		},
+		{
+			name:     "fields",
+			src:      &T{V: version{1, 2}, P: &version{3, 4}, L: []label{{"x"}}, LP: &label{"y"}},
+			expected: `&T{V: semver.New(1, 2), P: semver.New(3, 4), L: []label{newLabel("x")}, LP: newLabel("y")}`,
+		},
+		{
+			name:     "nil pointer",
+			src:      T{V: version{1, 0}},
+			expected: `T{V: semver.New(1, 0)}`,
+		},
+		{
+			name: "error",
+			src:  []version{{-1, 0}},
+			err:  "[0]: MarshalAST of astgen_test.version: invalid version",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
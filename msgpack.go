@@ -0,0 +1,267 @@
+package astgen
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go/ast"
+	"math"
+	"reflect"
+	"time"
+)
+
+// WithMsgpackExt makes BuildMsgpack decode the MessagePack extension type by
+// the function. The timestamp extension type (-1) is decoded to time.Time by
+// default.
+func WithMsgpackExt(typ int8, decode func([]byte) (any, error)) Option {
+	return func(b *builder) {
+		if b.msgpackExts == nil {
+			b.msgpackExts = make(map[int8]func([]byte) (any, error))
+		}
+		b.msgpackExts[typ] = decode
+	}
+}
+
+// BuildMsgpack builds the MessagePack encoded value, decoded by DecodeMsgpack
+// with the extension types configured by WithMsgpackExt.
+func BuildMsgpack(data []byte, opts ...Option) (ast.Node, error) {
+	b := newBuilder(opts)
+	v, err := DecodeMsgpack(data, b.msgpackExts)
+	if err != nil {
+		return nil, err
+	}
+	return b.buildNode(reflect.ValueOf(v))
+}
+
+// DecodeMsgpack decodes the MessagePack encoded value to a generic value.
+// Integers are decoded to int (or uint64 if overflows), floats to float32 or
+// float64, strings to string, binaries to []byte, arrays to []any, and maps
+// to map[string]any if all the keys are strings, otherwise to map[any]any.
+// The extension types are decoded by the functions in exts.
+func DecodeMsgpack(data []byte, exts map[int8]func([]byte) (any, error)) (any, error) {
+	d := &msgpackDecoder{data: data, exts: exts}
+	v, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	if d.offset < len(d.data) {
+		return nil, fmt.Errorf("msgpack: unexpected data after the value at offset %d", d.offset)
+	}
+	return v, nil
+}
+
+type msgpackDecoder struct {
+	data   []byte
+	offset int
+	exts   map[int8]func([]byte) (any, error)
+}
+
+var errMsgpackUnexpectedEOF = errors.New("msgpack: unexpected end of data")
+
+func (d *msgpackDecoder) read(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.offset < n {
+		return nil, errMsgpackUnexpectedEOF
+	}
+	bs := d.data[d.offset : d.offset+n]
+	d.offset += n
+	return bs, nil
+}
+
+func (d *msgpackDecoder) readUint(n int) (uint64, error) {
+	bs, err := d.read(n)
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 1:
+		return uint64(bs[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(bs)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(bs)), nil
+	default:
+		return binary.BigEndian.Uint64(bs), nil
+	}
+}
+
+func (d *msgpackDecoder) decode() (any, error) {
+	c, err := d.readUint(1)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return int(c), nil
+	case c <= 0x8f:
+		return d.decodeMap(int(c & 0x0f))
+	case c <= 0x9f:
+		return d.decodeArray(int(c & 0x0f))
+	case c <= 0xbf:
+		return d.decodeString(int(c & 0x1f))
+	case c >= 0xe0:
+		return int(int8(c)), nil
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readUint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		bs, err := d.read(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, bs...), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readUint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(int(n))
+	case 0xca:
+		u, err := d.readUint(4)
+		return math.Float32frombits(uint32(u)), err
+	case 0xcb:
+		u, err := d.readUint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.readUint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if u > math.MaxInt {
+			return u, nil
+		}
+		return int(u), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		n := 1 << (c - 0xd0)
+		u, err := d.readUint(n)
+		if err != nil {
+			return nil, err
+		}
+		// Sign-extend the n-byte integer.
+		return int(int64(u<<(64-8*n)) >> (64 - 8*n)), nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readUint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n))
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n))
+	default:
+		return nil, fmt.Errorf("msgpack: invalid format 0x%02x at offset %d", c, d.offset-1)
+	}
+}
+
+func (d *msgpackDecoder) decodeString(n int) (any, error) {
+	bs, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(bs), nil
+}
+
+func (d *msgpackDecoder) decodeArray(n int) (any, error) {
+	if n > len(d.data)-d.offset {
+		return nil, errMsgpackUnexpectedEOF
+	}
+	vs := make([]any, n)
+	for i := range vs {
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		vs[i] = v
+	}
+	return vs, nil
+}
+
+func (d *msgpackDecoder) decodeMap(n int) (any, error) {
+	if n > len(d.data)-d.offset {
+		return nil, errMsgpackUnexpectedEOF
+	}
+	keys, values := make([]any, n), make([]any, n)
+	strKeys := true
+	for i := 0; i < n; i++ {
+		k, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := k.(string); !ok {
+			strKeys = false
+		}
+		keys[i], values[i] = k, v
+	}
+	if strKeys {
+		m := make(map[string]any, n)
+		for i, k := range keys {
+			m[k.(string)] = values[i]
+		}
+		return m, nil
+	}
+	m := make(map[any]any, n)
+	for i, k := range keys {
+		switch k.(type) {
+		case []any, []byte, map[string]any, map[any]any:
+			return nil, fmt.Errorf("msgpack: unhashable map key: %v", k)
+		}
+		m[k] = values[i]
+	}
+	return m, nil
+}
+
+func (d *msgpackDecoder) decodeExt(n int) (any, error) {
+	typ, err := d.readUint(1)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := d.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if decode, ok := d.exts[int8(typ)]; ok {
+		return decode(bs)
+	}
+	if int8(typ) == -1 {
+		return decodeMsgpackTimestamp(bs)
+	}
+	return nil, fmt.Errorf("msgpack: unknown extension type %d", int8(typ))
+}
+
+func decodeMsgpackTimestamp(bs []byte) (any, error) {
+	switch len(bs) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(bs)), 0).UTC(), nil
+	case 8:
+		u := binary.BigEndian.Uint64(bs)
+		return time.Unix(int64(u&(1<<34-1)), int64(u>>34)).UTC(), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(bs[4:])),
+			int64(binary.BigEndian.Uint32(bs))).UTC(), nil
+	default:
+		return nil, fmt.Errorf("msgpack: invalid timestamp length %d", len(bs))
+	}
+}
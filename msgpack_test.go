@@ -0,0 +1,102 @@
+package astgen_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildMsgpack(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []byte
+		opts     []astgen.Option
+		expected string
+		err      string
+	}{
+		{
+			name:     "nil",
+			data:     []byte{0xc0},
+			expected: `nil`,
+		},
+		{
+			name:     "integers",
+			data:     []byte{0x95, 0x01, 0xff, 0xd0, 0x80, 0xcd, 0x12, 0x34, 0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			expected: "[]interface {\n}{interface {\n}(1), interface {\n}(-1), interface {\n}(-128), interface {\n}(4660), interface {\n}(uint64(18446744073709551615))}",
+		},
+		{
+			name:     "floats",
+			data:     []byte{0x92, 0xca, 0x3f, 0xc0, 0x00, 0x00, 0xcb, 0x40, 0x09, 0x21, 0xfb, 0x54, 0x44, 0x2d, 0x18},
+			expected: "[]interface {\n}{interface {\n}(float32(1.5)), interface {\n}(3.141592653589793)}",
+		},
+		{
+			name:     "map of string keys",
+			data:     []byte{0x82, 0xa1, 'a', 0xc3, 0xa1, 'b', 0xc4, 0x02, 'h', 'i'},
+			expected: "map[string]interface {\n}{\"a\": interface {\n}(true), \"b\": interface {\n}([]uint8{uint8(104), uint8(105)})}",
+		},
+		{
+			name:     "map of integer keys",
+			data:     []byte{0x82, 0x02, 0xa1, 'b', 0x01, 0xa1, 'a'},
+			expected: "map[interface {\n}]interface {\n}{interface {\n}(1): interface {\n}(\"a\"), interface {\n}(2): interface {\n}(\"b\")}",
+		},
+		{
+			name: "extension type with hint",
+			data: []byte{0xd5, 0x05, 0x01, 0x02},
+			opts: []astgen.Option{
+				astgen.WithMsgpackExt(5, func(bs []byte) (any, error) {
+					return int(binary.BigEndian.Uint16(bs)), nil
+				}),
+			},
+			expected: `258`,
+		},
+		{
+			name: "unknown extension type",
+			data: []byte{0xd5, 0x05, 0x01, 0x02},
+			err:  "msgpack: unknown extension type 5",
+		},
+		{
+			name: "unexpected end of data",
+			data: []byte{0x92, 0x01},
+			err:  "msgpack: unexpected end of data",
+		},
+		{
+			name: "unexpected data after the value",
+			data: []byte{0x01, 0x02},
+			err:  "msgpack: unexpected data after the value at offset 1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.BuildMsgpack(tc.data, tc.opts...)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestDecodeMsgpackTimestamp(t *testing.T) {
+	got, err := astgen.DecodeMsgpack([]byte{0xd6, 0xff, 0x5f, 0x5e, 0x10, 0x00}, nil)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if expected := "2020-09-13 12:26:40 +0000 UTC"; fmt.Sprint(got) != expected {
+		t.Errorf("expected: %s\ngot: %v", expected, got)
+	}
+}
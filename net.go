@@ -0,0 +1,79 @@
+package astgen
+
+import (
+	"go/ast"
+	"net"
+	"net/netip"
+	"reflect"
+	"strconv"
+)
+
+func init() {
+	builtinRules[reflect.TypeOf(net.IP(nil))] = (*builder).buildIP
+	builtinRules[reflect.TypeOf(netip.Addr{})] = func(b *builder, v reflect.Value) (ast.Expr, error) {
+		a := v.Interface().(netip.Addr)
+		if !a.IsValid() {
+			return &ast.CompositeLit{Type: b.selector("net/netip", "Addr")}, nil
+		}
+		return b.parseCall("net/netip", "MustParseAddr", a.String()), nil
+	}
+	builtinRules[reflect.TypeOf(netip.Prefix{})] = func(b *builder, v reflect.Value) (ast.Expr, error) {
+		p := v.Interface().(netip.Prefix)
+		switch {
+		case p.IsValid():
+			return b.parseCall("net/netip", "MustParsePrefix", p.String()), nil
+		case p == netip.Prefix{}:
+			return &ast.CompositeLit{Type: b.selector("net/netip", "Prefix")}, nil
+		default: // invalid number of the bits
+			a, err := b.buildExpr(reflect.ValueOf(p.Addr()))
+			if err != nil {
+				return nil, err
+			}
+			return &ast.CallExpr{
+				Fun:  b.selector("net/netip", "PrefixFrom"),
+				Args: []ast.Expr{a, intLit(int64(p.Bits()))},
+			}, nil
+		}
+	}
+	builtinRules[reflect.TypeOf(netip.AddrPort{})] = func(b *builder, v reflect.Value) (ast.Expr, error) {
+		ap := v.Interface().(netip.AddrPort)
+		if !ap.IsValid() {
+			return &ast.CompositeLit{Type: b.selector("net/netip", "AddrPort")}, nil
+		}
+		return b.parseCall("net/netip", "MustParseAddrPort", ap.String()), nil
+	}
+}
+
+// buildIP builds the IP address as the call of net.ParseIP. The 4-byte form
+// of the IPv4 address is kept by To4, and the bytes of the invalid length are
+// converted to net.IP as they are.
+func (b *builder) buildIP(v reflect.Value) (ast.Expr, error) {
+	ip := v.Interface().(net.IP)
+	if ip == nil {
+		return &ast.Ident{Name: "nil"}, nil
+	}
+	switch len(ip) {
+	case net.IPv4len:
+		return &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   b.parseCall("net", "ParseIP", ip.String()),
+				Sel: &ast.Ident{Name: "To4"},
+			},
+		}, nil
+	case net.IPv6len:
+		return b.parseCall("net", "ParseIP", ip.String()), nil
+	default:
+		return &ast.CallExpr{
+			Fun:  b.selector("net", "IP"),
+			Args: []ast.Expr{buildBytesLit(ip)},
+		}, nil
+	}
+}
+
+// parseCall builds the call of the parsing function of the package.
+func (b *builder) parseCall(pkgPath, name, s string) ast.Expr {
+	return &ast.CallExpr{
+		Fun:  b.selector(pkgPath, name),
+		Args: []ast.Expr{stringLit(strconv.Quote(s))},
+	}
+}
@@ -0,0 +1,71 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildNet(t *testing.T) {
+	type T struct {
+		IP     net.IP
+		Addr   netip.Addr
+		Prefix netip.Prefix
+		Listen netip.AddrPort
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "ip",
+			src:      []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("2001:db8::1"), net.IPv4(192, 168, 0, 1).To4(), nil},
+			expected: `[]net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("2001:db8::1"), net.ParseIP("192.168.0.1").To4(), nil}`,
+		},
+		{
+			name:     "invalid ip",
+			src:      net.IP{1, 2, 3},
+			expected: `net.IP([]byte("\x01\x02\x03"))`,
+		},
+		{
+			name:     "addr",
+			src:      []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("fe80::1%eth0"), {}},
+			expected: `[]netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("fe80::1%eth0"), {}}`,
+		},
+		{
+			name:     "prefix",
+			src:      []netip.Prefix{netip.MustParsePrefix("10.0.0.1/8"), {}, netip.PrefixFrom(netip.MustParseAddr("::1"), 200)},
+			expected: `[]netip.Prefix{netip.MustParsePrefix("10.0.0.1/8"), {}, netip.PrefixFrom(netip.MustParseAddr("::1"), -1)}`,
+		},
+		{
+			name: "struct",
+			src: T{
+				IP:     net.ParseIP("127.0.0.1"),
+				Addr:   netip.MustParseAddr("::1"),
+				Prefix: netip.MustParsePrefix("192.168.0.0/16"),
+				Listen: netip.MustParseAddrPort("[::1]:8080"),
+			},
+			expected: `astgen_test.T{IP: net.ParseIP("127.0.0.1"), Addr: netip.MustParseAddr("::1"), Prefix: netip.MustParsePrefix("192.168.0.0/16"), Listen: netip.MustParseAddrPort("[::1]:8080")}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, astgen.WithCurrentPackage("example.com/p"))
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
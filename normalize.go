@@ -0,0 +1,145 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// WithNormalize makes Build normalize the result with Normalize.
+func WithNormalize() Option {
+	return func(b *builder) {
+		b.normalize = true
+	}
+}
+
+// Normalize strips redundant parentheses and canonicalizes the tree before
+// printing. For example, (func(x int) *int { return &x })(42) is normalized
+// to func(x int) *int { return &x }(42), and complex64((1-2i)) to
+// complex64(1 - 2i). The node is modified in place and returned.
+func Normalize(n ast.Node) ast.Node {
+	if e, ok := n.(ast.Expr); ok {
+		n = normalizeExpr(e, false)
+	}
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ParenExpr:
+			n.X = normalizeExpr(n.X, false)
+		case *ast.CallExpr:
+			n.Fun = normalizeExpr(n.Fun, true)
+			normalizeExprs(n.Args)
+		case *ast.CompositeLit:
+			normalizeExprs(n.Elts)
+		case *ast.KeyValueExpr:
+			n.Key = normalizeExpr(n.Key, false)
+			n.Value = normalizeExpr(n.Value, false)
+		case *ast.SelectorExpr:
+			n.X = normalizeExpr(n.X, true)
+		case *ast.IndexExpr:
+			n.X = normalizeExpr(n.X, true)
+			n.Index = normalizeExpr(n.Index, false)
+		case *ast.StarExpr:
+			n.X = normalizeExpr(n.X, true)
+		case *ast.UnaryExpr:
+			n.X = normalizeExpr(n.X, true)
+		case *ast.BinaryExpr:
+			n.X = normalizeOperand(n.X, n.Op.Precedence()-1)
+			n.Y = normalizeOperand(n.Y, n.Op.Precedence())
+		case *ast.ReturnStmt:
+			normalizeExprs(n.Results)
+		case *ast.AssignStmt:
+			normalizeExprs(n.Rhs)
+		case *ast.ExprStmt:
+			n.X = normalizeExpr(n.X, false)
+		case *ast.ValueSpec:
+			normalizeExprs(n.Values)
+		}
+		return true
+	})
+	return n
+}
+
+func normalizeExprs(es []ast.Expr) {
+	for i, e := range es {
+		es[i] = normalizeExpr(e, false)
+	}
+}
+
+// normalizeExpr strips the parentheses around e. If strict is true, the
+// parentheses are kept unless the inner expression is a primary expression.
+func normalizeExpr(e ast.Expr, strict bool) ast.Expr {
+	for {
+		switch x := e.(type) {
+		case *ast.ParenExpr:
+			if strict && !isPrimaryExpr(x.X) {
+				x.X = normalizeExpr(x.X, false)
+				return x
+			}
+			e = x.X
+		case *ast.BasicLit:
+			y := normalizeComplexLit(x)
+			if y != ast.Expr(x) && strict {
+				return &ast.ParenExpr{X: y}
+			}
+			return y
+		default:
+			return e
+		}
+	}
+}
+
+// normalizeOperand strips the parentheses around an operand of a binary
+// expression if the operand binds tighter than prec.
+func normalizeOperand(e ast.Expr, prec int) ast.Expr {
+	if p, ok := e.(*ast.ParenExpr); ok {
+		if x, ok := normalizeExpr(p.X, false).(*ast.BinaryExpr); ok {
+			if x.Op.Precedence() > prec {
+				return x
+			}
+			p.X = x
+			return p
+		}
+	}
+	return normalizeExpr(e, true)
+}
+
+func isPrimaryExpr(e ast.Expr) bool {
+	switch e := e.(type) {
+	case *ast.Ident, *ast.CompositeLit, *ast.FuncLit, *ast.CallExpr,
+		*ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr,
+		*ast.SliceExpr, *ast.TypeAssertExpr, *ast.ParenExpr:
+		return true
+	case *ast.BasicLit:
+		return !strings.HasPrefix(e.Value, "(") && !strings.HasPrefix(e.Value, "-")
+	default:
+		return false
+	}
+}
+
+// normalizeComplexLit converts the complex literal formatted like (1-2i) to
+// a binary expression.
+func normalizeComplexLit(e *ast.BasicLit) ast.Expr {
+	if !strings.HasPrefix(e.Value, "(") || !strings.HasSuffix(e.Value, "i)") {
+		return e
+	}
+	s := e.Value[1 : len(e.Value)-1]
+	if strings.ContainsAny(s, "NI") { // NaN or Inf
+		return e
+	}
+	i := len(s) - 1
+	for i > 0 && (s[i] != '+' && s[i] != '-' || s[i-1] == 'e') {
+		i--
+	}
+	if i == 0 {
+		return e
+	}
+	op := token.ADD
+	if s[i] == '-' {
+		op = token.SUB
+	}
+	return &ast.BinaryExpr{
+		X:  &ast.BasicLit{Kind: token.FLOAT, Value: s[:i]},
+		Op: op,
+		Y:  &ast.BasicLit{Kind: token.IMAG, Value: s[i+1:]},
+	}
+}
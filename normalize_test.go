@@ -0,0 +1,97 @@
+package astgen_test
+
+import (
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithNormalize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "int",
+			src:      42,
+			expected: `42`,
+		},
+		{
+			name:     "complex64",
+			src:      complex64(1 - 2i),
+			expected: `complex64(1 - 2i)`,
+		},
+		{
+			name:     "complex128",
+			src:      -3.14156 + 2.71828i,
+			expected: `complex128(-3.14156 + 2.71828i)`,
+		},
+		{
+			name:     "complex128 with exponent",
+			src:      1e+21 - 1e-21i,
+			expected: `complex128(1e+21 - 1e-21i)`,
+		},
+		{
+			name: "pointer of literal",
+			src:  (func(i int) *int { return &i })(42),
+			expected: `func(x int) *int {
+	return &x
+}(42)`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, astgen.WithNormalize())
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		src, expected string
+	}{
+		{`((x))`, `x`},
+		{`f((x), (y + z))`, `f(x, y+z)`},
+		{`(f)(x)`, `f(x)`},
+		{`(*T)(nil)`, `(*T)(nil)`},
+		{`((*T))(nil)`, `(*T)(nil)`},
+		{`(x.y).z`, `x.y.z`},
+		{`(x + y).z`, `(x + y).z`},
+		{`(a * b) + (c * d)`, `a*b + c*d`},
+		{`(a + b) * (c + d)`, `(a + b) * (c + d)`},
+		{`(a - b) - (c - d)`, `a - b - (c - d)`},
+		{`-(x)`, `-x`},
+		{`-(-x)`, `-(-x)`},
+		{`[]int{(1), (2)}`, `[]int{1, 2}`},
+		{`map[int]int{(1): (2)}`, `map[int]int{1: 2}`},
+		{`(func() int { return (1) })()`, `func() int {
+	return 1
+}()`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			e, err := parser.ParseExpr(tc.src)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), astgen.Normalize(e))
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
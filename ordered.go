@@ -0,0 +1,114 @@
+package astgen
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+)
+
+// KeyValue is an entry of OrderedMap.
+type KeyValue struct {
+	Key, Value any
+}
+
+// OrderedMap is the interface implemented by the ordered maps, whose entries
+// are built in the order of KeyValues instead of the sorted order of the keys.
+// The ordered maps of map types are built as the literals of the types. The
+// other ordered maps, like the structs of linked entries, are built as the map
+// literals of the common types of the keys and the values, which fit the top
+// level and the interface values. The pairs of WithSortedPairs keep the order.
+type OrderedMap interface {
+	KeyValues() []KeyValue
+}
+
+var orderedMapType = reflect.TypeOf((*OrderedMap)(nil)).Elem()
+
+// orderedMap returns the map type, the keys and the values of the ordered
+// map, and reports whether the value implements OrderedMap.
+func orderedMap(v reflect.Value) (reflect.Type, []reflect.Value, []reflect.Value, bool, error) {
+	if !v.IsValid() {
+		return nil, nil, nil, false, nil
+	}
+	w, ok := methodReceiver(v, orderedMapType)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+	kvs := w.Interface().(OrderedMap).KeyValues()
+	t := v.Type()
+	if t.Kind() != reflect.Map {
+		ks, vs := make([]any, len(kvs)), make([]any, len(kvs))
+		for i, kv := range kvs {
+			ks[i], vs[i] = kv.Key, kv.Value
+		}
+		k := commonType(ks)
+		if !k.Comparable() {
+			return nil, nil, nil, true, fmt.Errorf("invalid key type %s of ordered map %s", k, v.Type())
+		}
+		t = reflect.MapOf(k, commonType(vs))
+	}
+	keys, values := make([]reflect.Value, len(kvs)), make([]reflect.Value, len(kvs))
+	seen := make(map[any]bool, len(kvs))
+	for i, kv := range kvs {
+		var err error
+		if keys[i], err = entryValue(kv.Key, t.Key()); err != nil {
+			return nil, nil, nil, true, fmt.Errorf("key of ordered map %s: %w", v.Type(), err)
+		}
+		if values[i], err = entryValue(kv.Value, t.Elem()); err != nil {
+			return nil, nil, nil, true, fmt.Errorf("value of ordered map %s: %w", v.Type(), err)
+		}
+		if k := keys[i].Interface(); keys[i].Comparable() {
+			if seen[k] {
+				return nil, nil, nil, true, fmt.Errorf("duplicate key %v of ordered map %s", k, v.Type())
+			}
+			seen[k] = true
+		}
+	}
+	return t, keys, values, true, nil
+}
+
+// commonType returns the type of the values if they are of the same type, or
+// the empty interface type otherwise.
+func commonType(xs []any) reflect.Type {
+	var t reflect.Type
+	for i, x := range xs {
+		if u := reflect.TypeOf(x); i == 0 {
+			t = u
+		} else if u != t {
+			t = nil
+			break
+		}
+	}
+	if t == nil {
+		return reflect.TypeOf((*any)(nil)).Elem()
+	}
+	return t
+}
+
+// entryValue returns the value of x as type t.
+func entryValue(x any, t reflect.Type) (reflect.Value, error) {
+	w := reflect.New(t).Elem()
+	if x == nil {
+		return w, nil
+	}
+	v := reflect.ValueOf(x)
+	if !v.Type().AssignableTo(t) {
+		return reflect.Value{}, fmt.Errorf("cannot use %s as %s", v.Type(), t)
+	}
+	w.Set(v)
+	return w, nil
+}
+
+func (b *builder) buildOrderedMap(t reflect.Type, keys, values []reflect.Value, elide bool) (ast.Expr, error) {
+	if err := b.checkNodes(2 * len(keys)); err != nil {
+		return nil, err
+	}
+	exprs, err := b.buildMapElts(reflect.Zero(t), keys, values)
+	if err != nil {
+		return nil, err
+	}
+	typ, err := b.buildLitType(t, elide)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.CompositeLit{Type: typ, Elts: exprs}, nil
+}
@@ -0,0 +1,109 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+type headers map[string]string
+
+func (h headers) KeyValues() []astgen.KeyValue {
+	var kvs []astgen.KeyValue
+	for _, k := range []string{"Host", "Accept", "Content-Type"} {
+		if v, ok := h[k]; ok {
+			kvs = append(kvs, astgen.KeyValue{Key: k, Value: v})
+		}
+	}
+	return kvs
+}
+
+type badKeys map[string]int
+
+func (badKeys) KeyValues() []astgen.KeyValue {
+	return []astgen.KeyValue{{Key: 1, Value: 1}}
+}
+
+type linkedMap struct {
+	entries []astgen.KeyValue
+}
+
+func (m *linkedMap) KeyValues() []astgen.KeyValue {
+	return m.entries
+}
+
+func TestBuildOrderedMap(t *testing.T) {
+	type T struct {
+		Headers headers
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		opts     []astgen.Option
+		expected string
+		err      string
+	}{
+		{
+			name:     "map type",
+			src:      T{Headers: headers{"Accept": "*/*", "Host": "example.com"}},
+			expected: `T{Headers: headers{"Host": "example.com", "Accept": "*/*"}}`,
+		},
+		{
+			name:     "linked map",
+			src:      &linkedMap{[]astgen.KeyValue{{Key: "z", Value: 1}, {Key: "a", Value: 2}}},
+			expected: `map[string]int{"z": 1, "a": 2}`,
+		},
+		{
+			name: "linked map in interface",
+			src:  []any{&linkedMap{[]astgen.KeyValue{{Key: "z", Value: 1}, {Key: "a", Value: "x"}}}},
+			expected: `[]interface {
+}{interface {
+}(map[string]interface {
+}{"z": interface {
+}(1), "a": interface {
+}("x")})}`,
+		},
+		{
+			name: "pairs",
+			src:  &linkedMap{[]astgen.KeyValue{{Key: "z", Value: 1}, {Key: "a", Value: 2}}},
+			opts: []astgen.Option{astgen.WithSortedPairs()},
+			expected: `[]struct {
+	Key	string
+	Value	int
+}{{Key: "z", Value: 1}, {Key: "a", Value: 2}}`,
+		},
+		{
+			name: "duplicate key",
+			src:  &linkedMap{[]astgen.KeyValue{{Key: "a", Value: 1}, {Key: "a", Value: 2}}},
+			err:  "duplicate key a of ordered map *astgen_test.linkedMap",
+		},
+		{
+			name: "unassignable key",
+			src:  []badKeys{{}},
+			err:  "[0]: key of ordered map astgen_test.badKeys: cannot use int as string",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, tc.opts...)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
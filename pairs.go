@@ -0,0 +1,77 @@
+package astgen
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+)
+
+// WithSortedPairs makes Build emit the maps as the slices of the key-value
+// pairs sorted by the keys, like []struct{ Key string; Value int }{{Key: "a",
+// Value: 1}}, so that the consuming program iterates the entries in order.
+// The keys of the numbers and strings are sorted in their natural order, and
+// the other keys are sorted by the expressions. Only the maps at the top level
+// and in the interface values are emitted as pairs, because the other maps,
+// like the struct fields of map types, are required to be maps.
+func WithSortedPairs() Option {
+	return func(b *builder) {
+		b.pairs = true
+	}
+}
+
+// sortedPairs converts the map to the slice of the key-value pairs if enabled.
+// The pairs of OrderedMap are in the order of the entries.
+func (b *builder) sortedPairs(v reflect.Value) (reflect.Value, error) {
+	if !b.pairs {
+		return v, nil
+	}
+	t, keys, values, ok, err := orderedMap(v)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if !ok {
+		if v.Kind() != reflect.Map || !v.CanInterface() {
+			return v, nil
+		}
+		if keys, err = b.sortMapKeys(v); err != nil {
+			return reflect.Value{}, err
+		}
+		sortKeys(keys, v.Type().Key().Kind())
+		t, values = v.Type(), make([]reflect.Value, len(keys))
+		for i, key := range keys {
+			values[i] = v.MapIndex(key)
+		}
+	}
+	pair := reflect.StructOf([]reflect.StructField{
+		{Name: "Key", Type: t.Key()},
+		{Name: "Value", Type: t.Elem()},
+	})
+	w := reflect.MakeSlice(reflect.SliceOf(pair), len(keys), len(keys))
+	for i, key := range keys {
+		w.Index(i).Field(0).Set(key)
+		w.Index(i).Field(1).Set(values[i])
+	}
+	return w, nil
+}
+
+// sortKeys sorts the keys of the numbers and strings in their natural order.
+func sortKeys(keys []reflect.Value, kind reflect.Kind) {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		slices.SortStableFunc(keys, func(k1, k2 reflect.Value) int {
+			return cmp.Compare(k1.Int(), k2.Int())
+		})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		slices.SortStableFunc(keys, func(k1, k2 reflect.Value) int {
+			return cmp.Compare(k1.Uint(), k2.Uint())
+		})
+	case reflect.Float32, reflect.Float64:
+		slices.SortStableFunc(keys, func(k1, k2 reflect.Value) int {
+			return cmp.Compare(k1.Float(), k2.Float())
+		})
+	case reflect.String:
+		slices.SortStableFunc(keys, func(k1, k2 reflect.Value) int {
+			return cmp.Compare(k1.String(), k2.String())
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithSortedPairs(t *testing.T) {
+	type T struct {
+		M map[string]int
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name: "map of int keys",
+			src:  map[int]string{10: "foo", 2: "bar", -1: "baz"},
+			expected: `[]struct {
+	Key	int
+	Value	string
+}{{Key: -1, Value: "baz"}, {Key: 2, Value: "bar"}, {Key: 10, Value: "foo"}}`,
+		},
+		{
+			name: "map in interface",
+			src:  map[string]any{"foo": map[string]any{"b": 1.0, "a": nil}},
+			expected: `[]struct {
+	Key	string
+	Value	interface {
+	}
+}{{Key: "foo", Value: interface {
+}([]struct {
+	Key	string
+	Value	interface {
+	}
+}{{Key: "a"}, {Key: "b", Value: interface {
+}(1.0)}})}}`,
+		},
+		{
+			name:     "map of struct field",
+			src:      T{M: map[string]int{"b": 1, "a": 2}},
+			expected: `T{M: map[string]int{"a": 2, "b": 1}}`,
+		},
+		{
+			name: "map of pointers",
+			src:  map[float64]*int{1.5: new(int)},
+			expected: `(func(x int) []struct {
+	Key	float64
+	Value	*int
+} {
+	return []struct {
+		Key	float64
+		Value	*int
+	}{{Key: 1.5, Value: &x}}
+})(0)`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, astgen.WithSortedPairs())
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
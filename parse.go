@@ -0,0 +1,62 @@
+package astgen
+
+import (
+	"encoding"
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strconv"
+)
+
+// WithParseFunc makes Build emit the values of the type as the calls of the
+// parsing function with the text of the values, like uuid.MustParse("...")
+// for the 16-byte UUID types, instead of the composite literals. The text is
+// the result of MarshalText, or String if the type does not implement
+// encoding.TextMarshaler. The function is qualified by the package name if
+// pkgPath is not empty, and the package is imported.
+func WithParseFunc(t reflect.Type, pkgPath, name string) Option {
+	return func(b *builder) {
+		if b.rules == nil {
+			b.rules = make(map[reflect.Type]func(reflect.Value) (ast.Expr, error))
+		}
+		b.rules[t] = func(v reflect.Value) (ast.Expr, error) {
+			s, err := valueText(v)
+			if err != nil {
+				return nil, err
+			}
+			var fn ast.Expr = &ast.Ident{Name: name}
+			if pkgPath != "" {
+				fn = b.selector(pkgPath, name)
+			}
+			return &ast.CallExpr{
+				Fun:  fn,
+				Args: []ast.Expr{stringLit(strconv.Quote(s))},
+			}, nil
+		}
+	}
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// valueText returns the text of the value by MarshalText or String. The value
+// is copied to call the methods of the pointer receivers.
+func valueText(v reflect.Value) (string, error) {
+	if !v.CanAddr() && v.CanInterface() {
+		w := reflect.New(v.Type()).Elem()
+		w.Set(v)
+		v = w
+	}
+	if w, ok := methodReceiver(v, textMarshalerType); ok {
+		bs, err := w.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("MarshalText of %s: %w", v.Type(), err)
+		}
+		return string(bs), nil
+	}
+	if w, ok := methodReceiver(v, stringerType); ok {
+		return w.Interface().(fmt.Stringer).String(), nil
+	}
+	return "", fmt.Errorf("%s has neither MarshalText nor String", v.Type())
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
@@ -0,0 +1,80 @@
+package astgen_test
+
+import (
+	"encoding/hex"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+type uuid [16]byte
+
+func (u uuid) String() string {
+	s := hex.EncodeToString(u[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+type level int
+
+func (l *level) MarshalText() ([]byte, error) {
+	return []byte([]string{"debug", "info"}[*l]), nil
+}
+
+func TestBuildWithParseFunc(t *testing.T) {
+	type T struct {
+		ID     uuid
+		Parent *uuid
+		Level  level
+	}
+	id := uuid{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	testCases := []struct {
+		name     string
+		src      any
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "package function",
+			src:      []uuid{id},
+			opts:     []astgen.Option{astgen.WithParseFunc(reflect.TypeOf(uuid{}), "github.com/google/uuid", "MustParse")},
+			expected: `[]uuid{uuid.MustParse("123e4567-e89b-12d3-a456-426614174000")}`,
+		},
+		{
+			name: "local function",
+			src:  T{ID: id, Parent: &id, Level: 1},
+			opts: []astgen.Option{
+				astgen.WithParseFunc(reflect.TypeOf(uuid{}), "", "mustParseUUID"),
+				astgen.WithParseFunc(reflect.TypeOf(level(0)), "", "mustParseLevel"),
+			},
+			expected: `(func(m uuid) T {
+	return T{ID: mustParseUUID("123e4567-e89b-12d3-a456-426614174000"), Parent: &m, Level: mustParseLevel("info")}
+})(mustParseUUID("123e4567-e89b-12d3-a456-426614174000"))`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildWithParseFuncError(t *testing.T) {
+	type T struct{ X int }
+	_, err := astgen.Build(T{}, astgen.WithParseFunc(reflect.TypeOf(T{}), "", "parseT"))
+	if expected := "astgen_test.T has neither MarshalText nor String"; err == nil || err.Error() != expected {
+		t.Errorf("expected error: %s\ngot: %v", expected, err)
+	}
+}
@@ -0,0 +1,15 @@
+package astgen
+
+import "reflect"
+
+// isProtoMessage reports whether the struct type is the message generated by
+// protoc-gen-go, which implements the ProtoReflect method on the pointer. The
+// unexported fields of the messages are the internal states of the runtime,
+// like state, sizeCache and unknownFields, which are not the contents.
+func isProtoMessage(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	m, ok := reflect.PointerTo(t).MethodByName("ProtoReflect")
+	return ok && m.Type.NumIn() == 1 && m.Type.NumOut() == 1
+}
@@ -0,0 +1,68 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+// protoMessage mimics the message generated by protoc-gen-go.
+type protoMessage struct {
+	state         struct{ mu sync.Mutex }
+	sizeCache     int32
+	unknownFields []byte
+
+	Name  string
+	Items []*protoMessage
+}
+
+func (*protoMessage) ProtoReflect() any { return nil }
+
+func TestBuildProtoMessage(t *testing.T) {
+	src := &protoMessage{
+		sizeCache: 12, unknownFields: []byte{0x08},
+		Name: "foo", Items: []*protoMessage{{sizeCache: 3, Name: "bar"}},
+	}
+	testCases := []struct {
+		name     string
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: `&protoMessage{Name: "foo", Items: []*protoMessage{{Name: "bar"}}}`,
+		},
+		{
+			name:     "current package",
+			opts:     []astgen.Option{astgen.WithCurrentPackage("example.com/p")},
+			expected: `&astgen_test.protoMessage{Name: "foo", Items: []*astgen_test.protoMessage{{Name: "bar"}}}`,
+		},
+		{
+			name: "keep",
+			opts: []astgen.Option{
+				astgen.WithUnexported(astgen.UnexportedKeep, reflect.TypeOf(protoMessage{})),
+			},
+			expected: `&protoMessage{sizeCache: int32(12), unknownFields: []uint8{uint8(8)}, Name: "foo", ` +
+				`Items: []*protoMessage{{sizeCache: int32(3), Name: "bar"}}}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(src, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
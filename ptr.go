@@ -0,0 +1,133 @@
+package astgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+)
+
+// WithPtrHelpers makes Build emit the pointers of the basic values as the
+// calls of a named helper function of each type like ptrString("x"), instead
+// of the helper variables of each value. FileBuilder declares the helper
+// functions once, like func ptrString(v string) *string { return &v }, and
+// Build binds them once as the helper variables.
+func WithPtrHelpers() Option {
+	return func(b *builder) {
+		b.ptrHelpers = true
+	}
+}
+
+// WithGenericPtrHelper makes Build emit the pointers of the basic values as
+// the calls of the generic helper function of the name like ptr(42), keeping
+// the defined types by the conversions like ptr(Level(1)), instead of the
+// helper functions of each type by WithPtrHelpers. FileBuilder declares the
+// function once, like func ptr[T any](v T) *T { return &v }, but the callers
+// of the other functions should declare it in the package. Build fails if the
+// name collides with the other identifiers in the generated code.
+func WithGenericPtrHelper(name string) Option {
+	return func(b *builder) {
+		b.ptrHelper = name
+	}
+}
+
+// isBasicKind reports whether the kind is of a boolean, numeric or string.
+func isBasicKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
+
+const ptrHelperTemplate = `func(v %[1]s) *%[1]s {
+	return &v
+}`
+
+func (b *builder) buildPtrHelperCall(v reflect.Value, e ast.Expr) (ast.Expr, error) {
+	t, err := b.buildType(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	ts := b.sprint(t)
+	fn := parseTemplate(fmt.Sprintf(ptrHelperTemplate, ts), nil)
+	ft := parseTemplate(fmt.Sprintf("func(%[1]s) *%[1]s", ts), nil)
+	return &ast.CallExpr{Fun: b.getHelperIdent(ptrHelperName(v.Type()), ft, fn), Args: []ast.Expr{e}}, nil
+}
+
+// ptrHelperName returns the name of the pointer helper function of the type.
+func ptrHelperName(t reflect.Type) string {
+	return "ptr" + helperTypeName(t)
+}
+
+// buildGenericPtrCall builds the pointer of the basic value as the call of
+// the generic helper function configured by WithGenericPtrHelper.
+func (b *builder) buildGenericPtrCall(v reflect.Value, e ast.Expr) ast.Expr {
+	if isDefinedLit(v.Type(), e) {
+		e = &ast.CallExpr{Fun: b.typeName(v.Type()), Args: []ast.Expr{e}}
+	}
+	id := &ast.Ident{Name: b.ptrHelper}
+	if b.ptrCalls == nil {
+		b.ptrCalls = make(map[*ast.Ident]bool)
+	}
+	b.ptrCalls[id] = true
+	return &ast.CallExpr{Fun: id, Args: []ast.Expr{e}}
+}
+
+// checkGenericPtrHelper checks the name of the generic helper function, which
+// should not collide with the other identifiers in the node, like the names
+// of the types and the other helpers.
+func (b *builder) checkGenericPtrHelper(n ast.Node) error {
+	if b.ptrHelper == "" {
+		return nil
+	}
+	if !token.IsIdentifier(b.ptrHelper) || isReservedName(b.ptrHelper) {
+		return fmt.Errorf("invalid pointer helper name: %q", b.ptrHelper)
+	}
+	var err error
+	ast.Inspect(n, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == b.ptrHelper && !b.ptrCalls[id] {
+			err = fmt.Errorf("pointer helper name %s collides with the identifier in the generated code", b.ptrHelper)
+		}
+		return err == nil
+	})
+	return err
+}
+
+const genericPtrHelperTemplate = `package p
+
+func %s[T any](v T) *T {
+	return &v
+}`
+
+// genericPtrHelperDecl returns the declaration of the generic helper
+// function configured by WithGenericPtrHelper, if called from the node.
+func (b *builder) genericPtrHelperDecl(n ast.Node) ast.Decl {
+	if b.ptrHelper == "" || !callsFunc(n, b.ptrHelper) {
+		return nil
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "", fmt.Sprintf(genericPtrHelperTemplate, b.ptrHelper), parser.SkipObjectResolution)
+	if err != nil {
+		panic(err)
+	}
+	clearPositions(f.Decls[0])
+	return f.Decls[0]
+}
+
+// callsFunc reports whether the node calls the function of the name.
+func callsFunc(n ast.Node, name string) bool {
+	var found bool
+	ast.Inspect(n, func(n ast.Node) bool {
+		if c, ok := n.(*ast.CallExpr); ok {
+			if id, ok := c.Fun.(*ast.Ident); ok && id.Name == name {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
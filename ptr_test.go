@@ -0,0 +1,183 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithPtrHelpers(t *testing.T) {
+	type name string
+	type T struct {
+		A *string
+		B *string
+		C *int
+		D *name
+		E *T
+	}
+	ptr := func(s string) *string { return &s }
+	n := name("x")
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name: "string pointer",
+			src:  ptr("foo"),
+			expected: `(func(ptrString func(string) *string) *string {
+	return ptrString("foo")
+})(func(v string) *string {
+	return &v
+})`,
+		},
+		{
+			name: "pointers in struct",
+			src:  T{A: ptr("foo"), B: ptr("bar"), C: new(int), D: &n, E: &T{A: ptr("baz")}},
+			expected: `(func(ptrString func(string) *string, ptrInt func(int) *int, ptrName func(name) *name) T {
+	return T{A: ptrString("foo"), B: ptrString("bar"), C: ptrInt(0), D: ptrName("x"), E: &T{A: ptrString("baz")}}
+})(func(v string) *string {
+	return &v
+}, func(v int) *int {
+	return &v
+}, func(v name) *name {
+	return &v
+})`,
+		},
+		{
+			name:     "nil pointer",
+			src:      T{},
+			expected: `T{}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, astgen.WithPtrHelpers())
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildWithGenericPtrHelper(t *testing.T) {
+	type level int
+	type T struct {
+		A *string
+		B *int
+		C *level
+		D *float64
+		E *uint8
+		F *T
+	}
+	s, n, l, f, u := "foo", 0, level(2), 1.5, uint8(3)
+	src := T{A: &s, B: &n, C: &l, D: &f, E: &u, F: &T{A: &s}}
+	got, err := astgen.Build(src, astgen.WithGenericPtrHelper("ptr"))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `T{A: ptr("foo"), B: ptr(0), C: ptr(level(2)), D: ptr(1.5), E: ptr(uint8(3)), F: &T{A: ptr("foo")}}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+	for _, tc := range []struct {
+		name string
+		err  string
+	}{
+		{"level", "pointer helper name level collides with the identifier in the generated code"},
+		{"new", `invalid pointer helper name: "new"`},
+		{"ptr-", `invalid pointer helper name: "ptr-"`},
+	} {
+		_, err := astgen.Build(src, astgen.WithGenericPtrHelper(tc.name))
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("expected: %s\ngot: %v", tc.err, err)
+		}
+	}
+}
+
+func TestFileBuilderWithGenericPtrHelper(t *testing.T) {
+	type T struct {
+		Name *string
+		Port *int
+	}
+	s, n := "foo", 8080
+	fb := astgen.NewFileBuilder("config", astgen.WithGenericPtrHelper("ptr"))
+	if err := fb.Add("x", T{Name: &s, Port: &n}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if err := fb.Add("y", T{}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	got, err := fb.Source()
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `package config
+
+var x = T{Name: ptr("foo"), Port: ptr(8080)}
+
+var y = T{}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+`
+	if string(got) != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, got)
+	}
+	if err := fb.Add("ptr", 1); err == nil {
+		t.Errorf("should return error")
+	}
+	fb = astgen.NewFileBuilder("config", astgen.WithGenericPtrHelper("ptr"))
+	if err := fb.Add("y", T{}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if got, err = fb.Source(); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if expected := "package config\n\nvar y = T{}\n"; string(got) != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, got)
+	}
+}
+
+func TestFileBuilderWithPtrHelpers(t *testing.T) {
+	type T struct {
+		Name *string
+		Port *int
+	}
+	s, n := "foo", 8080
+	fb := astgen.NewFileBuilder("config", astgen.WithPtrHelpers())
+	if err := fb.Add("x", T{Name: &s, Port: &n}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	got, err := fb.Source()
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `package config
+
+var x = T{Name: ptrString("foo"), Port: ptrInt(8080)}
+
+func ptrString(v string) *string {
+	return &v
+}
+
+func ptrInt(v int) *int {
+	return &v
+}
+`
+	if string(got) != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, got)
+	}
+}
@@ -0,0 +1,71 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+var (
+	reflectTypeType = reflect.TypeOf((*reflect.Type)(nil)).Elem()
+	rtypeType       = reflect.TypeOf(reflect.TypeOf(0))
+)
+
+// interfaceResults are the interface types of the expressions built for the
+// concrete types, like reflect.Type of reflect.TypeOf, which are not converted
+// to the interface types.
+var interfaceResults = map[reflect.Type]reflect.Type{
+	rtypeType: reflectTypeType,
+}
+
+func init() {
+	builtinRules[rtypeType] = func(b *builder, v reflect.Value) (ast.Expr, error) {
+		if v.IsNil() {
+			return &ast.Ident{Name: "nil"}, nil
+		}
+		return b.buildReflectType(v.Interface().(reflect.Type))
+	}
+}
+
+// buildReflectType builds the type as the call of reflect.TypeOf with the
+// zero value of the type, or with the nil pointer of the type for the types
+// without the literals of the zero values, like the interfaces and the funcs.
+func (b *builder) buildReflectType(t reflect.Type) (ast.Expr, error) {
+	var x ast.Expr
+	switch t {
+	case reflect.TypeOf(false):
+		x = &ast.Ident{Name: "false"}
+	case reflect.TypeOf(0):
+		x = &ast.BasicLit{Kind: token.INT, Value: "0"}
+	case reflect.TypeOf(0.0):
+		x = &ast.BasicLit{Kind: token.FLOAT, Value: "0.0"}
+	case reflect.TypeOf(""):
+		x = &ast.BasicLit{Kind: token.STRING, Value: `""`}
+	}
+	if x == nil {
+		e, err := b.buildType(t)
+		if err != nil {
+			return nil, err
+		}
+		switch t.Kind() {
+		case reflect.Array, reflect.Map, reflect.Slice, reflect.Struct:
+			x = &ast.CompositeLit{Type: e}
+		case reflect.Ptr:
+			x = &ast.CallExpr{Fun: &ast.ParenExpr{X: e}, Args: []ast.Expr{&ast.Ident{Name: "nil"}}}
+		default:
+			return &ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X: &ast.CallExpr{
+						Fun: b.selector("reflect", "TypeOf"),
+						Args: []ast.Expr{&ast.CallExpr{
+							Fun:  &ast.ParenExpr{X: &ast.StarExpr{X: e}},
+							Args: []ast.Expr{&ast.Ident{Name: "nil"}},
+						}},
+					},
+					Sel: &ast.Ident{Name: "Elem"},
+				},
+			}, nil
+		}
+	}
+	return &ast.CallExpr{Fun: b.selector("reflect", "TypeOf"), Args: []ast.Expr{x}}, nil
+}
@@ -0,0 +1,51 @@
+package astgen_test
+
+import (
+	"fmt"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildReflectType(t *testing.T) {
+	type Entry struct {
+		Name string
+	}
+	src := map[string]reflect.Type{
+		"bool":      reflect.TypeOf(false),
+		"int":       reflect.TypeOf(0),
+		"float64":   reflect.TypeOf(0.0),
+		"string":    reflect.TypeOf(""),
+		"uint8":     reflect.TypeOf(uint8(0)),
+		"duration":  reflect.TypeOf(time.Second),
+		"slice":     reflect.TypeOf([]int(nil)),
+		"map":       reflect.TypeOf(map[string]bool(nil)),
+		"struct":    reflect.TypeOf(Entry{}),
+		"pointer":   reflect.TypeOf(&Entry{}),
+		"interface": reflect.TypeOf((*fmt.Stringer)(nil)).Elem(),
+		"func":      reflect.TypeOf(func() {}),
+		"nil":       nil,
+	}
+	got, err := astgen.Build(src, astgen.WithCurrentPackage("example.com/p"))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `map[string]reflect.Type{"bool": reflect.TypeOf(false), ` +
+		`"duration": reflect.TypeOf((*time.Duration)(nil)).Elem(), ` +
+		`"float64": reflect.TypeOf(0.0), "func": reflect.TypeOf((*func())(nil)).Elem(), ` +
+		`"int": reflect.TypeOf(0), "interface": reflect.TypeOf((*fmt.Stringer)(nil)).Elem(), ` +
+		`"map": reflect.TypeOf(map[string]bool{}), "nil": reflect.Type(nil), ` +
+		`"pointer": reflect.TypeOf((*astgen_test.Entry)(nil)), "slice": reflect.TypeOf([]int{}), ` +
+		`"string": reflect.TypeOf(""), "struct": reflect.TypeOf(astgen_test.Entry{}), ` +
+		`"uint8": reflect.TypeOf((*uint8)(nil)).Elem()}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
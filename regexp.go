@@ -0,0 +1,40 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+func init() {
+	t := reflect.TypeOf((*regexp.Regexp)(nil))
+	builtinRules[t] = (*builder).buildRegexp
+	builtinRules[t.Elem()] = func(b *builder, v reflect.Value) (ast.Expr, error) {
+		r := v.Interface().(regexp.Regexp)
+		e, err := b.buildRegexp(reflect.ValueOf(&r))
+		if err != nil {
+			return nil, err
+		}
+		return &ast.StarExpr{X: e}, nil
+	}
+}
+
+// buildRegexp builds the regular expression as the call of regexp.MustCompile
+// with the source text. The patterns with the backslashes are quoted as the
+// raw strings. The leftmost-longest matching set by Longest is not kept.
+func (b *builder) buildRegexp(v reflect.Value) (ast.Expr, error) {
+	if v.IsNil() {
+		return &ast.Ident{Name: "nil"}, nil
+	}
+	s := v.Interface().(*regexp.Regexp).String()
+	lit := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(s)}
+	if lit.Value != `"`+s+`"` && strconv.CanBackquote(s) {
+		lit.Value = "`" + s + "`"
+	}
+	return &ast.CallExpr{
+		Fun:  b.selector("regexp", "MustCompile"),
+		Args: []ast.Expr{lit},
+	}, nil
+}
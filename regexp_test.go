@@ -0,0 +1,58 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildRegexp(t *testing.T) {
+	type T struct {
+		Pattern *regexp.Regexp
+		Filter  regexp.Regexp
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "simple",
+			src:      regexp.MustCompile("^foo.*bar$"),
+			expected: `regexp.MustCompile("^foo.*bar$")`,
+		},
+		{
+			name:     "backslashes",
+			src:      []*regexp.Regexp{regexp.MustCompile(`\d+\.\d+`), nil},
+			expected: "[]*regexp.Regexp{regexp.MustCompile(`\\d+\\.\\d+`), nil}",
+		},
+		{
+			name:     "newline",
+			src:      regexp.MustCompile("a\n\\s"),
+			expected: `regexp.MustCompile("a\n\\s")`,
+		},
+		{
+			name:     "struct",
+			src:      T{Pattern: regexp.MustCompile("x"), Filter: *regexp.MustCompile("(?i)y")},
+			expected: `astgen_test.T{Pattern: regexp.MustCompile("x"), Filter: *regexp.MustCompile("(?i)y")}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, astgen.WithCurrentPackage("example.com/p"))
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package astgen
+
+import (
+	"go/ast"
+	"path"
+	"reflect"
+)
+
+// rule builds the expression of a value of a specific type.
+type rule func(*builder, reflect.Value) (ast.Expr, error)
+
+// builtinRules are the rules of the types in the standard library, which
+// cannot be built from their fields.
+var builtinRules = map[reflect.Type]rule{}
+
+// selector builds the qualified identifier of the package and records the
+// package as imported.
+func (b *builder) selector(pkgPath, name string) *ast.SelectorExpr {
+	b.addImport(pkgPath)
+	return &ast.SelectorExpr{
+		X:   &ast.Ident{Name: path.Base(pkgPath)},
+		Sel: &ast.Ident{Name: name},
+	}
+}
+
+func (b *builder) addImport(pkgPath string) {
+	if b.imports == nil {
+		b.imports = make(map[string]bool)
+	}
+	b.imports[pkgPath] = true
+}
@@ -0,0 +1,93 @@
+package astgen
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// WithSetHelper makes Build emit the set-shaped maps like map[string]struct{}
+// as the calls of a helper function like newStringSet("a", "b", "c"), instead
+// of the entries like "a": struct{}{}. The helper function is included once
+// as a helper variable.
+func WithSetHelper() Option {
+	return func(b *builder) {
+		b.setHelper = true
+	}
+}
+
+// isSetType reports whether the type is a map of which values are empty
+// structs.
+func isSetType(t reflect.Type) bool {
+	return t.Kind() == reflect.Map &&
+		t.Elem().Kind() == reflect.Struct && t.Elem().NumField() == 0
+}
+
+const setHelperTemplate = `func(keys ...%[1]s) map[%[1]s]%[2]s {
+	s := make(map[%[1]s]%[2]s, len(keys))
+	for _, k := range keys {
+		s[k] = %[2]s{}
+	}
+	return s
+}`
+
+func (b *builder) buildSet(v reflect.Value) (ast.Expr, error) {
+	keys, err := b.sortMapKeys(v)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]ast.Expr, len(keys))
+	for i, key := range keys {
+		if args[i], err = b.buildExpr(key); err != nil {
+			return nil, err
+		}
+	}
+	k, err := b.buildType(v.Type().Key())
+	if err != nil {
+		return nil, err
+	}
+	e, err := b.buildType(v.Type().Elem())
+	if err != nil {
+		return nil, err
+	}
+	ks, es := b.sprint(k), b.sprint(e)
+	fn := parseTemplate(fmt.Sprintf(setHelperTemplate, ks, es), nil)
+	t := parseTemplate(fmt.Sprintf("func(...%[1]s) map[%[1]s]%[2]s", ks, es), nil)
+	return &ast.CallExpr{Fun: b.getHelperIdent(setHelperName(v.Type().Key()), t, fn), Args: args}, nil
+}
+
+// setHelperName returns the name of the set helper function of the key type.
+func setHelperName(t reflect.Type) string {
+	if name := helperTypeName(t); name != "" {
+		return "new" + name + "Set"
+	}
+	return "newSet"
+}
+
+// helperTypeName returns the capitalized name of the type for the names of
+// the helper functions, or an empty string if the type is unnamed or an
+// instantiated generic type.
+func helperTypeName(t reflect.Type) string {
+	name := t.Name()
+	if name == "" || strings.ContainsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// getHelperIdent returns the identifier of the helper variable of the exact
+// name, which is registered once.
+func (b *builder) getHelperIdent(name string, t, e ast.Expr) *ast.Ident {
+	for _, bv := range b.vars {
+		if reflect.DeepEqual(t, bv.typ) && reflect.DeepEqual(e, bv.expr) {
+			return bv.ident
+		}
+	}
+	ident := &ast.Ident{Name: b.newVarName(name, len(name), nil)}
+	b.vars = append(b.vars, builderVar{ident: ident, base: name, exact: true, typ: t, expr: e, helper: true})
+	return ident
+}
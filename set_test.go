@@ -0,0 +1,119 @@
+package astgen_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithSetHelper(t *testing.T) {
+	type T struct {
+		X map[string]struct{}
+		Y []map[int]struct{}
+		Z map[string]bool
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name: "set",
+			src:  map[string]struct{}{"b": {}, "a": {}, "c": {}},
+			expected: `(func(newStringSet func(...string) map[string]struct {
+}) map[string]struct {
+} {
+	return newStringSet("a", "b", "c")
+})(func(keys ...string) map[string]struct {
+} {
+	s := make(map[string]struct {
+	}, len(keys))
+	for _, k := range keys {
+		s[k] = struct {
+		}{}
+	}
+	return s
+})`,
+		},
+		{
+			name: "empty set",
+			src:  map[string]struct{}{},
+			expected: `map[string]struct {
+}{}`,
+		},
+		{
+			name: "sets in struct",
+			src: T{
+				X: map[string]struct{}{"foo": {}},
+				Y: []map[int]struct{}{{1: {}, 2: {}}, {3: {}}, {}},
+				Z: map[string]bool{"bar": true},
+			},
+			expected: `(func(newStringSet func(...string) map[string]struct {
+}, newIntSet func(...int) map[int]struct {
+}) T {
+	return T{X: newStringSet("foo"), Y: []map[int]struct {
+	}{newIntSet(1, 2), newIntSet(3), {}}, Z: map[string]bool{"bar": true}}
+})(func(keys ...string) map[string]struct {
+} {
+	s := make(map[string]struct {
+	}, len(keys))
+	for _, k := range keys {
+		s[k] = struct {
+		}{}
+	}
+	return s
+}, func(keys ...int) map[int]struct {
+} {
+	s := make(map[int]struct {
+	}, len(keys))
+	for _, k := range keys {
+		s[k] = struct {
+		}{}
+	}
+	return s
+})`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, astgen.WithSetHelper())
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildWithSetHelperTypeCheck(t *testing.T) {
+	type T struct {
+		X map[string]struct{}
+		Y *int
+	}
+	got, err := astgen.Build([]T{{X: map[string]struct{}{"a": {}}, Y: new(int)}, {X: map[string]struct{}{"b": {}}}},
+		astgen.WithSetHelper())
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	sb.WriteString("package p\n\ntype T struct {\n\tX map[string]struct{}\n\tY *int\n}\n\nvar _ = ")
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", sb.String(), 0)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if _, err = new(types.Config).Check("p", fset, []*ast.File{f}, nil); err != nil {
+		t.Errorf("should type check: %s\n%s", err, sb.String())
+	}
+}
@@ -0,0 +1,53 @@
+package astgen
+
+import (
+	"bytes"
+	"go/printer"
+	"go/token"
+	"io"
+	"maps"
+	"reflect"
+	"strings"
+)
+
+// printConfig is the configuration of the printer, which is the same as
+// gofmt, so that the same value is printed in the same layout.
+var printConfig = printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+
+// Source builds x and returns the source code of the expression formatted
+// like gofmt.
+func Source(x any, opts ...Option) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Fprint(&buf, x, opts...); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Print builds x and returns the source code of the expression formatted like
+// gofmt, as a string.
+func Print(x any, opts ...Option) (string, error) {
+	var sb strings.Builder
+	if err := Fprint(&sb, x, opts...); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// Fprint builds x and writes the source code of the expression formatted like
+// gofmt to w. Nothing is written if the build fails.
+func Fprint(w io.Writer, x any, opts ...Option) error {
+	b := newBuilder(opts)
+	n, err := b.buildNode(reflect.ValueOf(x))
+	if err != nil {
+		return err
+	}
+	// Assign the positions, so that the empty interfaces and structs are
+	// printed in one line.
+	fset := b.fileSet
+	if fset == nil {
+		fset = token.NewFileSet()
+		layoutNode(fset, n, nil, longLits(n, b.lineWidth, maps.Clone(b.rowLits)))
+	}
+	return printConfig.Fprint(w, fset, n)
+}
@@ -0,0 +1,154 @@
+package astgen_test
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestSource(t *testing.T) {
+	type T struct {
+		X int
+		Y *string
+		Z map[string]any
+	}
+	s := "foo"
+	got, err := astgen.Source(T{X: 1, Y: &s, Z: map[string]any{"a": []int{1}}})
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `(func(f string) T {
+	return T{X: 1, Y: &f, Z: map[string]interface{}{"a": interface{}([]int{1})}}
+})("foo")`
+	if string(got) != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, got)
+	}
+
+	_, err = astgen.Source(func() {})
+	if expected := "unexpected type: func"; err == nil || err.Error() != expected {
+		t.Errorf("expected: %s\ngot: %v", expected, err)
+	}
+}
+
+func TestFprint(t *testing.T) {
+	var sb strings.Builder
+	if err := astgen.Fprint(&sb, []any{1, "foo", nil}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `[]interface{}{interface{}(1), interface{}("foo"), interface{}(nil)}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+
+	sb.Reset()
+	err := astgen.Fprint(&sb, []any{make(chan int)})
+	if expected := "[0]: unexpected type: chan"; err == nil || err.Error() != expected {
+		t.Errorf("expected: %s\ngot: %v", expected, err)
+	}
+	if sb.Len() > 0 {
+		t.Errorf("should not write on error: %s", sb.String())
+	}
+}
+
+func TestSourceWithMultiline(t *testing.T) {
+	type Item struct {
+		Name string
+		Tags []string
+	}
+	src := []Item{
+		{Name: "alpha", Tags: []string{"a"}},
+		{Name: "beta", Tags: []string{"b", "c", "d", "e", "f", "g", "h", "i", "j"}},
+	}
+	got, err := astgen.Source(src, astgen.WithMultiline(40))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `[]Item{
+	{Name: "alpha", Tags: []string{"a"}},
+	{
+		Name: "beta",
+		Tags: []string{
+			"b",
+			"c",
+			"d",
+			"e",
+			"f",
+			"g",
+			"h",
+			"i",
+			"j",
+		},
+	},
+}`
+	if string(got) != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, got)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	type T struct {
+		X int
+		Y map[string]any
+	}
+	for i := 0; i < 3; i++ {
+		got, err := astgen.Print(T{X: 1, Y: map[string]any{"b": 2, "a": []any{struct{}{}}}})
+		if err != nil {
+			t.Fatalf("should not return error: %s", err)
+		}
+		expected := `T{X: 1, Y: map[string]interface{}{"a": interface{}([]interface{}{interface{}(struct{}{})}), "b": interface{}(2)}}`
+		if got != expected {
+			t.Errorf("expected: %s\ngot: %s", expected, got)
+		}
+	}
+}
+
+func TestBuildWithFileSet(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "config.go", `package config
+
+// Config is the default configuration.
+var Config = T{}
+
+func main() {}
+`, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	type T struct {
+		Names []string
+		Attrs map[string]any
+		Next  *T
+	}
+	got, err := astgen.Build(T{Names: []string{"foo", "bar"}, Attrs: map[string]any{"x": 1}, Next: &T{}},
+		astgen.WithFileSet(fset), astgen.WithMultiline(60))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if !got.Pos().IsValid() || fset.File(got.Pos()) == fset.File(f.Pos()) {
+		t.Errorf("should assign the positions in a new file: %v", fset.Position(got.Pos()))
+	}
+	f.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0] = got.(ast.Expr)
+	var sb strings.Builder
+	if err := format.Node(&sb, fset, f); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `package config
+
+// Config is the default configuration.
+var Config = T{
+	Names: []string{"foo", "bar"},
+	Attrs: map[string]interface{}{"x": interface{}(1)},
+	Next:  &T{},
+}
+
+func main() {}
+`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
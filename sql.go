@@ -0,0 +1,34 @@
+package astgen
+
+import (
+	"database/sql"
+	"go/ast"
+	"reflect"
+)
+
+func init() {
+	for _, v := range []any{
+		sql.NullBool{}, sql.NullByte{}, sql.NullFloat64{}, sql.NullInt16{},
+		sql.NullInt32{}, sql.NullInt64{}, sql.NullString{}, sql.NullTime{},
+	} {
+		builtinRules[reflect.TypeOf(v)] = (*builder).buildSQLNull
+	}
+}
+
+// buildSQLNull builds the nullable value of database/sql as the composite
+// literal of the fields, omitting the zero fields like the value of NULL.
+func (b *builder) buildSQLNull(v reflect.Value) (ast.Expr, error) {
+	var exprs []ast.Expr
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).IsZero() {
+			continue
+		}
+		name := v.Type().Field(i).Name
+		e, err := b.buildExpr(v.Field(i))
+		if err != nil {
+			return nil, wrapPath(err, fieldSegment(name))
+		}
+		exprs = append(exprs, &ast.KeyValueExpr{Key: &ast.Ident{Name: name}, Value: e})
+	}
+	return &ast.CompositeLit{Type: b.selector("database/sql", v.Type().Name()), Elts: exprs}, nil
+}
@@ -0,0 +1,65 @@
+package astgen_test
+
+import (
+	"database/sql"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildSQLNull(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "string",
+			src:      sql.NullString{String: "x", Valid: true},
+			expected: `sql.NullString{String: "x", Valid: true}`,
+		},
+		{
+			name:     "empty string",
+			src:      sql.NullString{Valid: true},
+			expected: `sql.NullString{Valid: true}`,
+		},
+		{
+			name:     "null",
+			src:      sql.NullInt64{},
+			expected: `sql.NullInt64{}`,
+		},
+		{
+			name:     "integers",
+			src:      []sql.NullInt32{{Int32: 1, Valid: true}, {}},
+			expected: `[]sql.NullInt32{{Int32: int32(1), Valid: true}, {}}`,
+		},
+		{
+			name:     "map",
+			src:      map[sql.NullBool]sql.NullFloat64{{Bool: true, Valid: true}: {Float64: 1.5, Valid: true}},
+			expected: `map[sql.NullBool]sql.NullFloat64{sql.NullBool{Bool: true, Valid: true}: {Float64: 1.5, Valid: true}}`,
+		},
+		{
+			name:     "time",
+			src:      &sql.NullTime{Time: time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC), Valid: true},
+			expected: `&sql.NullTime{Time: time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC), Valid: true}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, astgen.WithCurrentPackage("example.com/p"))
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
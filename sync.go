@@ -0,0 +1,134 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// syncTypes are the synchronization primitives of sync, whose zero values are
+// ready to use, and the states of which are not the contents of the structs.
+var syncTypes = map[reflect.Type]bool{
+	reflect.TypeOf(sync.Mutex{}):     true,
+	reflect.TypeOf(sync.RWMutex{}):   true,
+	reflect.TypeOf(sync.Once{}):      true,
+	reflect.TypeOf(sync.WaitGroup{}): true,
+}
+
+// SyncMapMode is the handling of the contents of sync.Map, which are not
+// accessible through the fields.
+type SyncMapMode int
+
+const (
+	// SyncMapKeep builds sync.Map like the other structs.
+	SyncMapKeep SyncMapMode = iota
+	// SyncMapStore builds sync.Map as the function literal storing the
+	// entries to a new map by Store.
+	SyncMapStore
+	// SyncMapPlain builds sync.Map as the map of the empty interfaces, which
+	// is not assignable to sync.Map but shows the contents.
+	SyncMapPlain
+)
+
+// WithSyncMap sets the handling of sync.Map. The entries are read by Range,
+// and sorted like the keys of the maps. The default mode is SyncMapKeep.
+func WithSyncMap(mode SyncMapMode) Option {
+	return func(b *builder) {
+		b.syncMap = mode
+	}
+}
+
+var syncMapType = reflect.TypeOf(sync.Map{})
+
+// isSyncMap reports whether the type is sync.Map or the pointer of it.
+func isSyncMap(t reflect.Type) bool {
+	return t == syncMapType || t.Kind() == reflect.Ptr && t.Elem() == syncMapType
+}
+
+// buildSyncMap builds sync.Map by the mode, or the pointer of it.
+func (b *builder) buildSyncMap(v reflect.Value) (ast.Expr, error) {
+	ptr := v.Kind() == reflect.Ptr
+	if ptr {
+		if v.IsNil() {
+			return &ast.Ident{Name: "nil"}, nil
+		}
+		v = v.Elem()
+	}
+	p, err := pointerOf(v)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[any]any)
+	p.Interface().(*sync.Map).Range(func(k, v any) bool {
+		m[k] = v
+		return true
+	})
+	e, err := b.buildExpr(reflect.ValueOf(m))
+	if err != nil || b.syncMap == SyncMapPlain {
+		return e, err
+	}
+	var args [][]ast.Expr
+	for _, e := range e.(*ast.CompositeLit).Elts {
+		kv := e.(*ast.KeyValueExpr)
+		args = append(args, []ast.Expr{unwrapInterface(kv.Key), unwrapInterface(kv.Value)})
+	}
+	e = storeFunc("m", b.selector("sync", "Map"), args)
+	if !ptr {
+		e = &ast.StarExpr{X: e}
+	}
+	return e, nil
+}
+
+// pointerOf returns the pointer of the synchronized value. The value is copied
+// if not addressable, which is safe because it is not shared, and the pointer
+// of the unexported field is made accessible.
+func pointerOf(v reflect.Value) (reflect.Value, error) {
+	if !v.CanAddr() {
+		if !v.CanInterface() {
+			return reflect.Value{}, &UnsupportedTypeError{Type: v.Type()}
+		}
+		w := reflect.New(v.Type()).Elem()
+		w.Set(v)
+		v = w
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())), nil
+}
+
+// storeFunc builds the function literal which calls Store of a new value of
+// the type with each of the arguments, and returns the pointer of the value.
+func storeFunc(name string, t ast.Expr, args [][]ast.Expr) *ast.CallExpr {
+	stmts := []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.Ident{Name: name}},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.Ident{Name: "new"}, Args: []ast.Expr{t}}},
+		},
+	}
+	for _, args := range args {
+		stmts = append(stmts, &ast.ExprStmt{X: &ast.CallExpr{
+			Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: name}, Sel: &ast.Ident{Name: "Store"}},
+			Args: args,
+		}})
+	}
+	stmts = append(stmts, &ast.ReturnStmt{Results: []ast.Expr{&ast.Ident{Name: name}}})
+	return &ast.CallExpr{Fun: &ast.FuncLit{
+		Type: &ast.FuncType{
+			Params:  &ast.FieldList{},
+			Results: &ast.FieldList{List: []*ast.Field{{Type: &ast.StarExpr{X: t}}}},
+		},
+		Body: &ast.BlockStmt{List: stmts},
+	}}
+}
+
+// unwrapInterface returns the operand of the conversion to the empty
+// interface, which is implicit in the arguments of the empty interfaces.
+func unwrapInterface(e ast.Expr) ast.Expr {
+	if c, ok := e.(*ast.CallExpr); ok && len(c.Args) == 1 {
+		if t, ok := c.Fun.(*ast.InterfaceType); ok && (t.Methods == nil || len(t.Methods.List) == 0) {
+			return c.Args[0]
+		}
+	}
+	return e
+}
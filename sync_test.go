@@ -0,0 +1,129 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildSyncPrimitives(t *testing.T) {
+	type Counter struct {
+		sync.Mutex
+		Name   string
+		Count  int
+		rw     sync.RWMutex
+		once   sync.Once
+		wg     sync.WaitGroup
+		Shared *sync.Mutex
+	}
+	src := &Counter{Name: "foo", Count: 2}
+	src.Lock()
+	src.rw.RLock()
+	src.once.Do(func() {})
+	src.wg.Add(1)
+	testCases := []struct {
+		name     string
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: `&Counter{Name: "foo", Count: 2}`,
+		},
+		{
+			name: "unexported error",
+			opts: []astgen.Option{
+				astgen.WithCurrentPackage("example.com/p"),
+				astgen.WithUnexported(astgen.UnexportedError),
+			},
+			expected: `&astgen_test.Counter{Name: "foo", Count: 2}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(src, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildWithSyncMap(t *testing.T) {
+	type Registry struct {
+		Name  string
+		items *sync.Map
+	}
+	items := new(sync.Map)
+	items.Store("foo", 1)
+	items.Store("bar", []string{"baz"})
+	items.Store(2, time.Second)
+	value := &struct{ M sync.Map }{}
+	value.M.Store(true, nil)
+	testCases := []struct {
+		name     string
+		src      any
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name: "store",
+			src:  &Registry{Name: "x", items: items},
+			opts: []astgen.Option{astgen.WithSyncMap(astgen.SyncMapStore)},
+			expected: `&astgen_test.Registry{Name: "x", items: func() *sync.Map {
+	m := new(sync.Map)
+	m.Store("bar", []string{"baz"})
+	m.Store("foo", 1)
+	m.Store(2, time.Second)
+	return m
+}()}`,
+		},
+		{
+			name:     "empty",
+			src:      struct{ M *sync.Map }{new(sync.Map)},
+			opts:     []astgen.Option{astgen.WithSyncMap(astgen.SyncMapStore)},
+			expected: "struct{ M *sync.Map }{M: func() *sync.Map {\n\tm := new(sync.Map)\n\treturn m\n}()}",
+		},
+		{
+			name:     "value",
+			src:      value,
+			opts:     []astgen.Option{astgen.WithSyncMap(astgen.SyncMapStore)},
+			expected: "&struct{ M sync.Map }{M: *func() *sync.Map {\n\tm := new(sync.Map)\n\tm.Store(true, nil)\n\treturn m\n}()}",
+		},
+		{
+			name:     "nil",
+			src:      struct{ M *sync.Map }{},
+			opts:     []astgen.Option{astgen.WithSyncMap(astgen.SyncMapStore)},
+			expected: "struct{ M *sync.Map }{}",
+		},
+		{
+			name:     "plain",
+			src:      items,
+			opts:     []astgen.Option{astgen.WithSyncMap(astgen.SyncMapPlain)},
+			expected: `map[interface{}]interface{}{interface{}("bar"): interface{}([]string{"baz"}), interface{}("foo"): interface{}(1), interface{}(2): interface{}(time.Second)}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Source(tc.src, append(tc.opts, astgen.WithCurrentPackage("example.com/p"))...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			if string(got) != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package astgen
+
+import (
+	"encoding"
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strconv"
+)
+
+// WithTextHelper makes Build emit the values of the types implementing both
+// encoding.TextMarshaler and encoding.TextUnmarshaler as the calls of a helper
+// function unmarshaling the marshaled text, like mustParseLevel("info"). This
+// covers the types of other packages with the unexported fields, but the
+// builtin rules, the registered rules and ASTMarshaler take precedence. The
+// helper function is included once as a helper variable.
+func WithTextHelper() Option {
+	return func(b *builder) {
+		b.textHelper = true
+	}
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// isTextType reports whether the values of the type can be built by the text
+// helper. The pointers are excluded because the helper returns the values.
+func isTextType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return false
+	}
+	return methodKindOf(t, textMarshalerType) != methodNone &&
+		reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+const textHelperTemplate = `func(s string) %[1]s {
+	var x %[1]s
+	if err := x.UnmarshalText([]byte(s)); err != nil {
+		panic(err)
+	}
+	return x
+}`
+
+func (b *builder) buildTextHelperCall(v reflect.Value) (ast.Expr, error) {
+	if !v.CanAddr() && v.CanInterface() {
+		w := reflect.New(v.Type()).Elem()
+		w.Set(v)
+		v = w
+	}
+	w, ok := methodReceiver(v, textMarshalerType)
+	if !ok {
+		return nil, &UnsupportedTypeError{Type: v.Type()}
+	}
+	bs, err := w.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil, fmt.Errorf("MarshalText of %s: %w", v.Type(), err)
+	}
+	t, err := b.buildType(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	ts := b.sprint(t)
+	fn := parseTemplate(fmt.Sprintf(textHelperTemplate, ts), nil)
+	ft := parseTemplate(fmt.Sprintf("func(string) %s", ts), nil)
+	return &ast.CallExpr{
+		Fun:  b.getHelperIdent("mustParse"+helperTypeName(v.Type()), ft, fn),
+		Args: []ast.Expr{stringLit(strconv.Quote(string(bs)))},
+	}, nil
+}
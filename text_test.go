@@ -0,0 +1,54 @@
+package astgen_test
+
+import (
+	"errors"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+type rgb struct{ r, g, b uint8 }
+
+func (c rgb) MarshalText() ([]byte, error) {
+	return []byte{"0123456789abcdef"[c.r>>4], "0123456789abcdef"[c.r&15],
+		"0123456789abcdef"[c.g>>4], "0123456789abcdef"[c.g&15],
+		"0123456789abcdef"[c.b>>4], "0123456789abcdef"[c.b&15]}, nil
+}
+
+func (c *rgb) UnmarshalText([]byte) error {
+	return errors.New("not implemented")
+}
+
+func TestBuildWithTextHelper(t *testing.T) {
+	type T struct {
+		Foreground rgb
+		Background *rgb
+		Palette    []rgb
+	}
+	got, err := astgen.Build(T{
+		Foreground: rgb{0xff, 0x80, 0},
+		Background: &rgb{},
+		Palette:    []rgb{{1, 2, 3}, {0xff, 0x80, 0}},
+	}, astgen.WithTextHelper())
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `(func(mustParseRgb func(string) rgb) T {
+	m := mustParseRgb("000000")
+	return T{Foreground: mustParseRgb("ff8000"), Background: &m, Palette: []rgb{mustParseRgb("010203"), mustParseRgb("ff8000")}}
+})(func(s string) rgb {
+	var x rgb
+	if err := x.UnmarshalText([]byte(s)); err != nil {
+		panic(err)
+	}
+	return x
+})`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
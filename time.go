@@ -0,0 +1,121 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+func init() {
+	builtinRules[reflect.TypeOf(time.Time{})] = (*builder).buildTime
+	builtinRules[reflect.TypeOf(time.Duration(0))] = (*builder).buildDuration
+}
+
+// buildTime builds the time as the call of time.Date, like
+//
+//	time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+//
+// The monotonic clock reading is discarded.
+func (b *builder) buildTime(v reflect.Value) (ast.Expr, error) {
+	t := v.Interface().(time.Time)
+	if t.IsZero() {
+		return &ast.CompositeLit{Type: b.selector("time", "Time")}, nil
+	}
+	return &ast.CallExpr{
+		Fun: b.selector("time", "Date"),
+		Args: []ast.Expr{
+			intLit(int64(t.Year())), b.selector("time", t.Month().String()),
+			intLit(int64(t.Day())), intLit(int64(t.Hour())), intLit(int64(t.Minute())),
+			intLit(int64(t.Second())), intLit(int64(t.Nanosecond())),
+			b.buildLocation(t),
+		},
+	}, nil
+}
+
+const loadLocationTemplate = `func() *time.Location {
+	l, err := time.LoadLocation(NAME)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}()`
+
+// buildLocation builds the location of the time. The locations of the time
+// zone database are loaded by the name, and the others are built as the
+// fixed zones of the offsets of the time.
+func (b *builder) buildLocation(t time.Time) ast.Expr {
+	switch loc := t.Location(); loc {
+	case time.UTC:
+		return b.selector("time", "UTC")
+	case time.Local:
+		return b.selector("time", "Local")
+	default:
+		name := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(loc.String())}
+		if _, err := time.LoadLocation(loc.String()); err == nil && loc.String() != "" {
+			b.addImport("time")
+			return parseTemplate(loadLocationTemplate, map[string]ast.Expr{"NAME": name})
+		}
+		_, offset := t.Zone()
+		return &ast.CallExpr{
+			Fun:  b.selector("time", "FixedZone"),
+			Args: []ast.Expr{name, intLit(int64(offset))},
+		}
+	}
+}
+
+// durationUnits are the units of the durations in the descending order.
+var durationUnits = []struct {
+	name string
+	unit time.Duration
+}{
+	{"Hour", time.Hour}, {"Minute", time.Minute}, {"Second", time.Second},
+	{"Millisecond", time.Millisecond}, {"Microsecond", time.Microsecond},
+	{"Nanosecond", time.Nanosecond},
+}
+
+// buildDuration builds the duration as the sum of the units, like
+//
+//	5*time.Second + 30*time.Millisecond
+//
+// The negative duration is built like -time.Hour - 30*time.Minute. The sum
+// is typed by the units, and the zero duration is built as time.Duration(0)
+// regardless of the name of the type in the current package.
+func (b *builder) buildDuration(v reflect.Value) (ast.Expr, error) {
+	d := time.Duration(v.Int())
+	if d == 0 {
+		return callExpr(token.INT, b.selector("time", "Duration"), "0"), nil
+	}
+	u := uint64(d)
+	if d < 0 {
+		u = -u
+	}
+	var e ast.Expr
+	for _, du := range durationUnits {
+		n := u / uint64(du.unit)
+		if n == 0 {
+			continue
+		}
+		u -= n * uint64(du.unit)
+		var term ast.Expr = b.selector("time", du.name)
+		if n > 1 {
+			var x ast.Expr = &ast.BasicLit{Kind: token.INT, Value: strconv.FormatUint(n, 10)}
+			if d < 0 && e == nil {
+				x = &ast.UnaryExpr{Op: token.SUB, X: x}
+			}
+			term = &ast.BinaryExpr{X: x, Op: token.MUL, Y: term}
+		} else if d < 0 && e == nil {
+			term = &ast.UnaryExpr{Op: token.SUB, X: term}
+		}
+		switch {
+		case e == nil:
+			e = term
+		case d < 0:
+			e = &ast.BinaryExpr{X: e, Op: token.SUB, Y: term}
+		default:
+			e = &ast.BinaryExpr{X: e, Op: token.ADD, Y: term}
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,141 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database is not available: %s", err)
+	}
+	type T struct {
+		Created time.Time
+		Updated *time.Time
+	}
+	updated := time.Date(2024, 12, 31, 23, 59, 59, 999999999, time.UTC)
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "utc",
+			src:      time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+			expected: `time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)`,
+		},
+		{
+			name:     "zero",
+			src:      []time.Time{{}},
+			expected: `[]time.Time{{}}`,
+		},
+		{
+			name:     "fixed zone",
+			src:      time.Date(2024, 1, 2, 3, 4, 5, 6, time.FixedZone("JST", 9*60*60)),
+			expected: `time.Date(2024, time.January, 2, 3, 4, 5, 6, time.FixedZone("JST", 32400))`,
+		},
+		{
+			name: "location",
+			src:  time.Date(2024, 7, 4, 9, 0, 0, 0, newYork),
+			expected: `time.Date(2024, time.July, 4, 9, 0, 0, 0, func() *time.Location {
+	l, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		panic(err)
+	}
+	return l
+}())`,
+		},
+		{
+			name: "struct",
+			src:  T{Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Updated: &updated},
+			expected: `(func(t time.Time) T {
+	return T{Created: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), Updated: &t}
+})(time.Date(2024, time.December, 31, 23, 59, 59, 999999999, time.UTC))`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildTimeFile(t *testing.T) {
+	fb := astgen.NewFileBuilder("testdata")
+	if err := fb.Add("epoch", time.Unix(0, 0).UTC()); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	got, err := fb.Source()
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	expected := `package testdata
+
+import "time"
+
+var epoch = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+`
+	if string(got) != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, got)
+	}
+}
+
+func TestBuildDuration(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "units",
+			src:      []time.Duration{time.Second, 5*time.Second + 30*time.Millisecond, 90 * time.Minute, 1500},
+			expected: `[]time.Duration{time.Second, 5*time.Second + 30*time.Millisecond, time.Hour + 30*time.Minute, time.Microsecond + 500*time.Nanosecond}`,
+		},
+		{
+			name:     "zero and negative",
+			src:      []time.Duration{0, -time.Second, -90 * time.Minute, math.MinInt64},
+			expected: `[]time.Duration{time.Duration(0), -time.Second, -time.Hour - 30*time.Minute, -2562047*time.Hour - 47*time.Minute - 16*time.Second - 854*time.Millisecond - 775*time.Microsecond - 808*time.Nanosecond}`,
+		},
+		{
+			name: "interface",
+			src:  []any{2 * time.Hour},
+			expected: `[]interface {
+}{interface {
+}(2 * time.Hour)}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []astgen.Option{astgen.WithCurrentPackage("example.com/p")}
+			got, err := astgen.Build(tc.src, opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+			if err := astgen.Check(tc.src, opts...); err != nil {
+				t.Errorf("should not return error: %s", err)
+			}
+		})
+	}
+}
@@ -1,58 +1,86 @@
 package astgen
 
 import (
-	"fmt"
+	"encoding/json"
 	"go/ast"
 	"go/token"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
-func buildType(t reflect.Type) (ast.Expr, error) {
+// BuildType builds the type expression of t. The named types are referred by
+// the names, qualified if configured by WithCurrentPackage.
+func BuildType(t reflect.Type, opts ...Option) (ast.Expr, error) {
+	return newBuilder(opts).buildType(t)
+}
+
+func (b *builder) buildType(t reflect.Type) (ast.Expr, error) {
+	if e, ok := b.typeAliases[t]; ok {
+		return cloneNode(e).(ast.Expr), nil
+	}
 	if t.Name() != "" {
-		return &ast.Ident{Name: t.Name()}, nil
+		return b.typeName(t), nil
 	}
+	return b.buildTypeLit(t)
+}
+
+// buildTypeLit builds the type literal of t, which is the underlying type of
+// the named type.
+func (b *builder) buildTypeLit(t reflect.Type) (ast.Expr, error) {
 	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return &ast.Ident{Name: t.Kind().String()}, nil
 	case reflect.Interface:
-		return &ast.InterfaceType{Methods: &ast.FieldList{}}, nil
+		ms := make([]*ast.Field, t.NumMethod())
+		for i := range ms {
+			m := t.Method(i)
+			f, err := b.buildType(m.Type)
+			if err != nil {
+				return nil, err
+			}
+			ms[i] = &ast.Field{Names: []*ast.Ident{{Name: m.Name}}, Type: f}
+		}
+		return &ast.InterfaceType{Methods: &ast.FieldList{List: ms}}, nil
 	case reflect.Array:
-		elem, err := buildType(t.Elem())
+		elem, err := b.buildType(t.Elem())
 		if err != nil {
 			return nil, err
 		}
 		return &ast.ArrayType{
-			Len: &ast.BasicLit{Kind: token.INT, Value: fmt.Sprint(t.Len())},
+			Len: &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(t.Len())},
 			Elt: elem,
 		}, nil
 	case reflect.Slice:
-		elem, err := buildType(t.Elem())
+		elem, err := b.buildType(t.Elem())
 		if err != nil {
 			return nil, err
 		}
 		return &ast.ArrayType{Elt: elem}, nil
 	case reflect.Map:
-		k, err := buildType(t.Key())
+		k, err := b.buildType(t.Key())
 		if err != nil {
 			return nil, err
 		}
-		v, err := buildType(t.Elem())
+		v, err := b.buildType(t.Elem())
 		if err != nil {
 			return nil, err
 		}
 		return &ast.MapType{Key: k, Value: v}, nil
 	case reflect.Struct:
-		if t.Name() != "" {
-			return &ast.Ident{Name: t.Name()}, nil
-		}
 		fs := make([]*ast.Field, 0, t.NumField())
 		var prevType ast.Expr
 		var prevTag reflect.StructTag
 		for i := 0; i < t.NumField(); i++ {
 			sf := t.Field(i)
-			t, err := buildType(sf.Type)
+			t, err := b.buildType(sf.Type)
 			if err != nil {
 				return nil, err
 			}
-			if reflect.DeepEqual(prevType, t) && prevTag == sf.Tag {
+			if !sf.Anonymous && reflect.DeepEqual(prevType, t) && prevTag == sf.Tag {
 				fs[len(fs)-1].Names = append(fs[len(fs)-1].Names, &ast.Ident{Name: sf.Name})
 				continue
 			}
@@ -60,6 +88,11 @@ func buildType(t reflect.Type) (ast.Expr, error) {
 			if sf.Tag != "" {
 				tag = &ast.BasicLit{Value: "`" + string(sf.Tag) + "`"}
 			}
+			if sf.Anonymous { // embedded field like *Base
+				fs = append(fs, &ast.Field{Type: t, Tag: tag})
+				prevType = nil
+				continue
+			}
 			fs = append(fs, &ast.Field{
 				Names: []*ast.Ident{{Name: sf.Name}},
 				Type:  t,
@@ -69,12 +102,159 @@ func buildType(t reflect.Type) (ast.Expr, error) {
 		}
 		return &ast.StructType{Fields: &ast.FieldList{List: fs}}, nil
 	case reflect.Ptr:
-		t, err := buildType(t.Elem())
+		t, err := b.buildType(t.Elem())
 		if err != nil {
 			return nil, err
 		}
 		return &ast.StarExpr{X: t}, nil
+	case reflect.Chan:
+		elem, err := b.buildType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		var dir ast.ChanDir
+		switch t.ChanDir() {
+		case reflect.SendDir:
+			dir = ast.SEND
+		case reflect.RecvDir:
+			dir = ast.RECV
+		default:
+			dir = ast.SEND | ast.RECV
+			if t.Elem().Name() == "" && t.Elem().Kind() == reflect.Chan &&
+				t.Elem().ChanDir() == reflect.RecvDir {
+				elem = &ast.ParenExpr{X: elem} // chan (<-chan T)
+			}
+		}
+		return &ast.ChanType{Dir: dir, Value: elem}, nil
+	case reflect.Func:
+		params := make([]*ast.Field, t.NumIn())
+		for i := range params {
+			in := t.In(i)
+			if t.IsVariadic() && i == len(params)-1 {
+				in = in.Elem()
+			}
+			p, err := b.buildType(in)
+			if err != nil {
+				return nil, err
+			}
+			if t.IsVariadic() && i == len(params)-1 {
+				p = &ast.Ellipsis{Elt: p}
+			}
+			params[i] = &ast.Field{Type: p}
+		}
+		results := make([]*ast.Field, t.NumOut())
+		for i := range results {
+			r, err := b.buildType(t.Out(i))
+			if err != nil {
+				return nil, err
+			}
+			results[i] = &ast.Field{Type: r}
+		}
+		return &ast.FuncType{
+			Params:  &ast.FieldList{List: params},
+			Results: &ast.FieldList{List: results},
+		}, nil
 	default:
-		return nil, &unexpectedTypeError{t}
+		return nil, &UnsupportedTypeError{Type: t}
+	}
+}
+
+// WithCurrentPackage makes Build qualify the named types with the package
+// names, except for the types of the package of path, which is the package
+// of the generated code. The packages of the qualified types are recorded
+// as imported.
+func WithCurrentPackage(path string) Option {
+	return func(b *builder) {
+		b.qualify, b.currentPkg = true, path
+	}
+}
+
+// WithTypeAlias makes Build refer to the type by the expression, like the
+// name of a local type declared by BuildType. This shrinks the code of the
+// values of an anonymous struct type used repeatedly.
+func WithTypeAlias(t reflect.Type, name ast.Expr) Option {
+	return func(b *builder) {
+		if b.typeAliases == nil {
+			b.typeAliases = make(map[reflect.Type]ast.Expr)
+		}
+		b.typeAliases[t] = name
+	}
+}
+
+// Qualifier returns the expression of the named type of the package path
+// and the name, like a selector pkg.Name, or nil to name it by default.
+type Qualifier func(pkgPath, name string) ast.Expr
+
+// WithQualifier makes Build name the named types by the qualifier, which takes
+// precedence over WithCurrentPackage. The packages of the types named by the
+// selectors are recorded as imported.
+func WithQualifier(q Qualifier) Option {
+	return func(b *builder) {
+		b.qualifier = q
+	}
+}
+
+// builtinTypeNames are the qualified names of the types in the standard
+// library, which cannot be declared in the current package, or are aliases
+// of the types of the other names, like json.RawMessage of jsontext.Value.
+var builtinTypeNames = map[reflect.Type][2]string{
+	unsafePointerType:                 {"unsafe", "Pointer"},
+	reflect.TypeOf(json.RawMessage{}): {"encoding/json", "RawMessage"},
+}
+
+// isBuiltinRuleType reports whether the type of the standard library is built
+// by the builtin rules.
+func isBuiltinRuleType(t reflect.Type) bool {
+	_, ok := builtinRules[t]
+	return ok || isSyncMap(t) || isAtomic(t)
+}
+
+// typeName builds the name of the named type, qualified if enabled and the
+// type is not of the current package. The instantiated generic type is built
+// as the index expression of the type arguments.
+func (b *builder) typeName(t reflect.Type) ast.Expr {
+	if e, ok := b.typeAliases[t]; ok {
+		return cloneNode(e).(ast.Expr)
+	}
+	if name, ok := builtinTypeNames[t]; ok {
+		return b.selector(name[0], name[1])
+	}
+	if !b.qualify && isBuiltinRuleType(t) && !strings.Contains(t.Name(), "[") {
+		// The expressions of the builtin rules are qualified.
+		return b.selector(t.PkgPath(), t.Name())
+	}
+	// The package name is not always the last element of the path, like
+	// gopkg.in/yaml.v3, but the string of the type contains it.
+	pkgName, _, _ := strings.Cut(t.String(), ".")
+	name, args, ok := strings.Cut(t.Name(), "[")
+	e := b.qualifiedName(t.PkgPath(), pkgName, name)
+	if !ok {
+		return e
+	}
+	if e := b.instantiate(e, t.PkgPath(), pkgName, "["+args); e != nil {
+		return e
+	}
+	return &ast.Ident{Name: t.Name()}
+}
+
+// qualifiedName builds the name of the package path, qualified by the
+// qualifier, or by the package name if enabled and the path is not of the
+// current package.
+func (b *builder) qualifiedName(pkgPath, pkgName, name string) ast.Expr {
+	if b.qualifier != nil && pkgPath != "" {
+		if e := b.qualifier(pkgPath, name); e != nil {
+			if _, ok := e.(*ast.SelectorExpr); ok {
+				b.addImport(pkgPath)
+			}
+			return e
+		}
+	}
+	if !b.qualify || pkgPath == "" || pkgPath == b.currentPkg {
+		return &ast.Ident{Name: name}
+	}
+	b.addImport(pkgPath)
+	return &ast.SelectorExpr{
+		X:   &ast.Ident{Name: pkgName},
+		Sel: &ast.Ident{Name: name},
 	}
 }
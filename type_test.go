@@ -0,0 +1,255 @@
+package astgen_test
+
+import (
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithCurrentPackage(t *testing.T) {
+	type T struct {
+		D []time.Duration
+		M time.Month
+	}
+	src := T{D: []time.Duration{time.Second, 0}, M: time.March}
+	testCases := []struct {
+		name     string
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: `T{D: []time.Duration{time.Second, time.Duration(0)}, M: 3}`,
+		},
+		{
+			name:     "same package",
+			opts:     []astgen.Option{astgen.WithCurrentPackage("github.com/itchyny/astgen-go_test")},
+			expected: `T{D: []time.Duration{time.Second, time.Duration(0)}, M: 3}`,
+		},
+		{
+			name:     "other package",
+			opts:     []astgen.Option{astgen.WithCurrentPackage("example.com/p")},
+			expected: `astgen_test.T{D: []time.Duration{time.Second, time.Duration(0)}, M: 3}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(src, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildWithQualifier(t *testing.T) {
+	type T struct {
+		D []time.Duration
+		W []time.Weekday
+	}
+	fb := astgen.NewFileBuilder("p", astgen.WithQualifier(func(pkgPath, name string) ast.Expr {
+		switch {
+		case pkgPath == "time" && name == "Weekday":
+			return &ast.Ident{Name: "weekday"}
+		case pkgPath == "time":
+			return &ast.SelectorExpr{X: &ast.Ident{Name: "time"}, Sel: &ast.Ident{Name: name}}
+		default:
+			return nil
+		}
+	}))
+	if err := fb.Add("x", T{D: []time.Duration{time.Second}, W: []time.Weekday{time.Monday}}); err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), fb.File())
+	expected := `package p
+
+import "time"
+
+var x = T{D: []time.Duration{time.Second}, W: []weekday{1}}
+`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
+
+func TestBuildWithTypeAlias(t *testing.T) {
+	type row = struct {
+		ID   int
+		Name string
+	}
+	src := []row{{1, "foo"}, {2, "bar"}}
+	opts := []astgen.Option{astgen.WithTypeAlias(reflect.TypeOf(row{}), &ast.Ident{Name: "rowT"})}
+	got, err := astgen.Build(src, opts...)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `[]rowT{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+	if err := astgen.Check(src, opts...); err != nil {
+		t.Errorf("should not return error: %s", err)
+	}
+}
+
+type base struct {
+	ID int
+}
+
+func TestBuildEmbeddedPointer(t *testing.T) {
+	type T struct {
+		*base
+		Name string
+	}
+	type U struct {
+		*T
+		X int
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "named struct",
+			src:      T{&base{1}, "foo"},
+			expected: `T{base: &base{ID: 1}, Name: "foo"}`,
+		},
+		{
+			name:     "nil pointer",
+			src:      T{Name: "foo"},
+			expected: `T{Name: "foo"}`,
+		},
+		{
+			name:     "multi-level",
+			src:      []*U{{&T{&base{1}, "foo"}, 2}, {T: &T{Name: "bar"}}, {X: 3}},
+			expected: `[]*U{{T: &T{base: &base{ID: 1}, Name: "foo"}, X: 2}, {T: &T{Name: "bar"}}, {X: 3}}`,
+		},
+		{
+			name:     "qualified",
+			src:      U{T: &T{base: &base{}}},
+			opts:     []astgen.Option{astgen.WithCurrentPackage("example.com/p")},
+			expected: `astgen_test.U{T: &astgen_test.T{base: &astgen_test.base{}}}`,
+		},
+		{
+			name: "unnamed struct",
+			src: []struct {
+				*base
+				Name string
+			}{{&base{1}, "foo"}, {Name: "bar"}},
+			expected: `[]struct {
+	*base
+	Name	string
+}{{base: &base{ID: 1}, Name: "foo"}, {Name: "bar"}}`,
+		},
+		{
+			name: "embedded types",
+			src: []struct {
+				base
+				*T
+				X, Y int
+			}{{X: 1}},
+			expected: `[]struct {
+	base
+	*T
+	X, Y	int
+}{{X: 1}}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+			if len(tc.opts) > 0 { // the test package cannot be imported
+				return
+			}
+			if err := astgen.Check(tc.src); err != nil {
+				t.Errorf("should not return error: %s", err)
+			}
+		})
+	}
+}
+
+func TestBuildType(t *testing.T) {
+	type T struct {
+		X, Y int
+		Z    *time.Time
+	}
+	testCases := []struct {
+		name     string
+		typ      reflect.Type
+		opts     []astgen.Option
+		expected string
+	}{
+		{
+			name:     "named",
+			typ:      reflect.TypeOf(T{}),
+			expected: `T`,
+		},
+		{
+			name:     "composite",
+			typ:      reflect.TypeOf(map[string][]*[2]int{}),
+			expected: `map[string][]*[2]int`,
+		},
+		{
+			name: "struct",
+			typ: reflect.TypeOf(struct {
+				X, Y int
+				Z    *time.Time
+			}{}),
+			opts: []astgen.Option{astgen.WithCurrentPackage("example.com/p")},
+			expected: `struct {
+	X, Y	int
+	Z	*time.Time
+}`,
+		},
+		{
+			name: "interface",
+			typ:  reflect.TypeOf((*interface{ Get(string) (int, error) })(nil)).Elem(),
+			expected: `interface {
+	Get(string) (int, error)
+}`,
+		},
+		{
+			name:     "func",
+			typ:      reflect.TypeOf(func(string, ...int) {}),
+			expected: `func(string, ...int)`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.BuildType(tc.typ, tc.opts...)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
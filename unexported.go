@@ -0,0 +1,83 @@
+package astgen
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// UnexportedMode is the handling of the unexported fields of the structs of
+// other packages, which cannot be set by the composite literals outside of
+// the packages.
+type UnexportedMode int
+
+const (
+	// UnexportedKeep builds the unexported fields like the other fields, or
+	// reconstructs the structs if enabled by WithReflectFallback.
+	UnexportedKeep UnexportedMode = iota
+	// UnexportedSkip omits the unexported fields, leaving them zero.
+	UnexportedSkip
+	// UnexportedError reports an error on the unexported field with the path
+	// of the field, unless the field is omitted.
+	UnexportedError
+)
+
+// WithUnexported sets the handling of the unexported fields of the structs
+// of other packages, which are determined by WithCurrentPackage. Without the
+// current package, every package is regarded as the other package, so the
+// mode applies to all the unexported fields. The mode is applied to the
+// types if specified, otherwise to all the types. The default
+// mode is UnexportedKeep, except for the protobuf messages, whose unexported
+// fields are skipped in any package unless the mode of the type is set. To
+// call the constructor of a type instead, register the rule by
+// Builder.Register, or implement ASTMarshaler.
+func WithUnexported(mode UnexportedMode, types ...reflect.Type) Option {
+	return func(b *builder) {
+		if len(types) == 0 {
+			b.unexported = mode
+			return
+		}
+		if b.unexpTypes == nil {
+			b.unexpTypes = make(map[reflect.Type]UnexportedMode)
+		}
+		for _, t := range types {
+			b.unexpTypes[t] = mode
+		}
+	}
+}
+
+// unexportedMode returns the handling of the unexported fields of the type.
+func (b *builder) unexportedMode(t reflect.Type) UnexportedMode {
+	if mode, ok := b.unexpTypes[t]; ok {
+		return mode
+	}
+	if isProtoMessage(t) {
+		return UnexportedSkip
+	}
+	return b.unexported
+}
+
+// isInaccessibleField reports whether the field is the unexported field of
+// other packages, which cannot be set from the current package.
+func (b *builder) isInaccessibleField(sf reflect.StructField) bool {
+	return b.qualify && !sf.IsExported() && sf.PkgPath != b.currentPkg
+}
+
+// checkUnexported reports whether the field should be skipped, or returns
+// the error of the field by the mode of the struct type. Without the current
+// package, all the unexported fields are checked.
+func (b *builder) checkUnexported(t reflect.Type, sf reflect.StructField) (bool, error) {
+	if sf.IsExported() || b.qualify && !b.isInaccessibleField(sf) && !isProtoMessage(t) {
+		return false, nil
+	}
+	switch b.unexportedMode(t) {
+	case UnexportedSkip:
+		return true, nil
+	case UnexportedError:
+		return false, wrapPath(
+			fmt.Errorf("unexported field %s of %s", sf.Name, t),
+			fieldSegment(sf.Name),
+		)
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,105 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithUnexported(t *testing.T) {
+	type Box struct {
+		Secrets []Secret
+		size    int
+	}
+	src := Box{Secrets: []Secret{{id: 1, Tags: []string{"foo"}}, {Tags: []string{"bar"}}}, size: 2}
+	pkg := astgen.WithCurrentPackage("example.com/p")
+	testCases := []struct {
+		name     string
+		opts     []astgen.Option
+		expected string
+		err      string
+	}{
+		{
+			name:     "keep",
+			opts:     []astgen.Option{pkg, astgen.WithUnexported(astgen.UnexportedKeep)},
+			expected: `astgen_test.Box{Secrets: []astgen_test.Secret{{id: uint16(1), Tags: []string{"foo"}}, {Tags: []string{"bar"}}}, size: 2}`,
+		},
+		{
+			name:     "skip",
+			opts:     []astgen.Option{pkg, astgen.WithUnexported(astgen.UnexportedSkip)},
+			expected: `astgen_test.Box{Secrets: []astgen_test.Secret{{Tags: []string{"foo"}}, {Tags: []string{"bar"}}}}`,
+		},
+		{
+			name: "error",
+			opts: []astgen.Option{pkg, astgen.WithUnexported(astgen.UnexportedError)},
+			err:  "Box.Secrets[0].id: unexported field id of astgen_test.Secret",
+		},
+		{
+			name: "per type",
+			opts: []astgen.Option{
+				pkg, astgen.WithUnexported(astgen.UnexportedError),
+				astgen.WithUnexported(astgen.UnexportedSkip, reflect.TypeOf(Box{})),
+			},
+			err: "Box.Secrets[0].id: unexported field id of astgen_test.Secret",
+		},
+		{
+			name: "skip per type",
+			opts: []astgen.Option{
+				pkg, astgen.WithUnexported(astgen.UnexportedError),
+				astgen.WithUnexported(astgen.UnexportedSkip, reflect.TypeOf(Box{}), reflect.TypeOf(Secret{})),
+			},
+			expected: `astgen_test.Box{Secrets: []astgen_test.Secret{{Tags: []string{"foo"}}, {Tags: []string{"bar"}}}}`,
+		},
+		{
+			name: "error without current package",
+			opts: []astgen.Option{astgen.WithUnexported(astgen.UnexportedError)},
+			err:  "Box.Secrets[0].id: unexported field id of astgen_test.Secret",
+		},
+		{
+			name:     "skip without current package",
+			opts:     []astgen.Option{astgen.WithUnexported(astgen.UnexportedSkip)},
+			expected: `Box{Secrets: []Secret{{Tags: []string{"foo"}}, {Tags: []string{"bar"}}}}`,
+		},
+		{
+			name:     "keep without current package",
+			opts:     []astgen.Option{astgen.WithUnexported(astgen.UnexportedKeep)},
+			expected: `Box{Secrets: []Secret{{id: uint16(1), Tags: []string{"foo"}}, {Tags: []string{"bar"}}}, size: 2}`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(src, tc.opts...)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildWithUnexportedSkipWithoutCurrentPackage(t *testing.T) {
+	got, err := astgen.Build(strings.NewReader("foo"), astgen.WithUnexported(astgen.UnexportedSkip))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	if expected := `&Reader{}`; sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
@@ -0,0 +1,56 @@
+package astgen
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"unsafe"
+)
+
+// UnsafePointerMode is the handling of the unsafe pointers.
+type UnsafePointerMode int
+
+const (
+	// UnsafePointerError reports an error on the unsafe pointers, or builds
+	// them as nil if configured by WithUnsupportedAsZero.
+	UnsafePointerError UnsafePointerMode = iota
+	// UnsafePointerNil builds the unsafe pointers as nil.
+	UnsafePointerNil
+	// UnsafePointerAddress builds the unsafe pointers as the conversions of
+	// the addresses like unsafe.Pointer(uintptr(0xc000012345)), which are
+	// only valid while the memory is retained, like in the same process.
+	UnsafePointerAddress
+)
+
+var unsafePointerType = reflect.TypeOf(unsafe.Pointer(nil))
+
+// WithUnsafePointer sets the handling of the unsafe pointers. The default mode
+// is UnsafePointerError.
+func WithUnsafePointer(mode UnsafePointerMode) Option {
+	return func(b *builder) {
+		b.unsafePtr = mode
+	}
+}
+
+func (b *builder) buildUnsafePointer(v reflect.Value) (ast.Expr, error) {
+	switch b.unsafePtr {
+	case UnsafePointerNil:
+		return &ast.Ident{Name: "nil"}, nil
+	case UnsafePointerAddress:
+		if v.IsNil() {
+			return &ast.Ident{Name: "nil"}, nil
+		}
+		return &ast.CallExpr{
+			Fun:  b.typeName(v.Type()),
+			Args: []ast.Expr{buildUintptr(&ast.Ident{Name: "uintptr"}, uint64(v.Pointer()))},
+		}, nil
+	default:
+		return b.buildUnsupported(v)
+	}
+}
+
+// buildUintptr builds the conversion of the address in hexadecimal.
+func buildUintptr(t ast.Expr, u uint64) ast.Expr {
+	return callExpr(token.INT, t, "0x"+strconv.FormatUint(u, 16))
+}
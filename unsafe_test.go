@@ -0,0 +1,80 @@
+package astgen_test
+
+import (
+	"fmt"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildUintptr(t *testing.T) {
+	type addr uintptr
+	got, err := astgen.Build([]any{uintptr(0), uintptr(0xc000012345), addr(255)})
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `[]interface {
+}{interface {
+}(uintptr(0x0)), interface {
+}(uintptr(0xc000012345)), interface {
+}(addr(0xff))}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
+
+func TestBuildWithUnsafePointer(t *testing.T) {
+	type T struct {
+		P unsafe.Pointer
+		Q unsafe.Pointer
+	}
+	x := 1
+	src := T{P: unsafe.Pointer(&x)}
+	testCases := []struct {
+		name     string
+		opts     []astgen.Option
+		expected string
+		err      string
+	}{
+		{
+			name: "error",
+			err:  "T.P: unexpected type: unsafe.Pointer",
+		},
+		{
+			name:     "nil",
+			opts:     []astgen.Option{astgen.WithUnsafePointer(astgen.UnsafePointerNil)},
+			expected: `T{P: nil}`,
+		},
+		{
+			name:     "address",
+			opts:     []astgen.Option{astgen.WithUnsafePointer(astgen.UnsafePointerAddress)},
+			expected: fmt.Sprintf(`T{P: unsafe.Pointer(uintptr(%#x))}`, uintptr(unsafe.Pointer(&x))),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(src, tc.opts...)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error: %s\ngot: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
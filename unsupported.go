@@ -0,0 +1,29 @@
+package astgen
+
+import (
+	"go/ast"
+	"reflect"
+	"strings"
+)
+
+// WithUnsupportedAsZero makes Build emit the values which cannot be built,
+// like the channels, the functions and the unsafe pointers, as the zero
+// values instead of reporting an error. The warn function, if not nil, is
+// called with the path like Config.Workers[3].Done and the value.
+func WithUnsupportedAsZero(warn func(path string, v reflect.Value)) Option {
+	return func(b *builder) {
+		b.unsupZero, b.unsupWarn = true, warn
+	}
+}
+
+// buildUnsupported builds the zero value of the unsupported value if
+// configured by WithUnsupportedAsZero, otherwise returns the error.
+func (b *builder) buildUnsupported(v reflect.Value) (ast.Expr, error) {
+	if !b.unsupZero {
+		return nil, &UnsupportedTypeError{Type: v.Type()}
+	}
+	if b.unsupWarn != nil && !b.scratch {
+		b.unsupWarn(strings.Join(b.path, ""), v)
+	}
+	return &ast.Ident{Name: "nil"}, nil
+}
@@ -0,0 +1,71 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildWithUnsupportedAsZero(t *testing.T) {
+	type Worker struct {
+		Name string
+		Done chan struct{}
+		Run  func() error
+	}
+	type Config struct {
+		Workers []*Worker
+		Hooks   map[string]any
+		Ptr     unsafe.Pointer
+	}
+	x := 1
+	src := Config{
+		Workers: []*Worker{{Name: "a"}, {Name: "b", Done: make(chan struct{}), Run: func() error { return nil }}},
+		Hooks:   map[string]any{"x": func() {}},
+		Ptr:     unsafe.Pointer(&x),
+	}
+	var warnings []string
+	got, err := astgen.Build(src, astgen.WithUnsupportedAsZero(func(path string, v reflect.Value) {
+		warnings = append(warnings, path+": "+v.Type().String())
+	}))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `Config{Workers: []*Worker{{Name: "a"}, {Name: "b", Done: nil, Run: nil}}, Hooks: map[string]interface {
+}{"x": interface {
+}((func())(nil))}, Ptr: nil}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+	expectedWarnings := []string{
+		"Config.Workers[1].Done: chan struct {}",
+		"Config.Workers[1].Run: func() error",
+		`Config.Hooks["x"]: func()`,
+		"Config.Ptr: unsafe.Pointer",
+	}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("expected: %q\ngot: %q", expectedWarnings, warnings)
+	}
+}
+
+func TestBuildWithUnsupportedAsZeroNoWarn(t *testing.T) {
+	got, err := astgen.Build([]any{make(chan int), unsafe.Pointer(nil)}, astgen.WithUnsupportedAsZero(nil))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `[]interface {
+}{interface {
+}((chan int)(nil)), interface {
+}((unsafe.Pointer)(nil))}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
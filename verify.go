@@ -0,0 +1,86 @@
+package astgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// Verify builds x and compares it with the value of the top-level variable or
+// constant declaration of name in the Go source file at path, ignoring the
+// formatting differences and comments. When the file is stale, the returned
+// error describes the difference in the diff format.
+func Verify(path, name string, x any, opts ...Option) error {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return err
+	}
+	expected := lookupValue(f, name)
+	if expected == nil {
+		return fmt.Errorf("%s: declaration of %s not found", path, name)
+	}
+	got, err := Build(x, opts...)
+	if err != nil {
+		return err
+	}
+	s, t := canonicalString(expected), canonicalString(got)
+	if s == t {
+		return nil
+	}
+	return &StaleError{File: path, Name: name, Diff: DiffLines(path, "generated", s, t)}
+}
+
+func lookupValue(f *ast.File, name string) ast.Expr {
+	for _, d := range f.Decls {
+		d, ok := d.(*ast.GenDecl)
+		if !ok || d.Tok != token.VAR && d.Tok != token.CONST {
+			continue
+		}
+		for _, s := range d.Specs {
+			s := s.(*ast.ValueSpec)
+			for i, n := range s.Names {
+				if n.Name == name && i < len(s.Values) {
+					return s.Values[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// canonicalString prints the node without the positions to ignore formatting.
+func canonicalString(n ast.Node) string {
+	clearPositions(n)
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), Normalize(n))
+	return sb.String()
+}
+
+func clearPositions(n ast.Node) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		v := reflect.ValueOf(n).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.Type() == reflect.TypeOf(token.NoPos) {
+				f.SetInt(int64(token.NoPos))
+			}
+		}
+		return true
+	})
+}
+
+// StaleError is the error of Verify when the declaration is stale.
+type StaleError struct {
+	File, Name string
+	Diff       string // difference in the diff format
+}
+
+func (err *StaleError) Error() string {
+	return fmt.Sprintf("%s: %s is stale:\n%s", err.File, err.Name, err.Diff)
+}
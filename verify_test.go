@@ -0,0 +1,50 @@
+package astgen_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.go")
+	err := os.WriteFile(path, []byte(`package data
+
+// data is generated.
+var data = map[string][]int{
+	"a": {1, 2, 3}, // comment
+	"b": {
+		4,
+		5,
+	},
+}
+
+const x = 1
+`), 0o644)
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	if err = astgen.Verify(path, "data", map[string][]int{"a": {1, 2, 3}, "b": {4, 5}}); err != nil {
+		t.Errorf("should not return error: %s", err)
+	}
+	if err = astgen.Verify(path, "x", 1); err != nil {
+		t.Errorf("should not return error: %s", err)
+	}
+	err = astgen.Verify(path, "data", map[string][]int{"a": {1, 2, 3}, "b": {4, 6}})
+	expected := path + `: data is stale:
+--- ` + path + `
++++ generated
+-map[string][]int{"a": {1, 2, 3}, "b": {4, 5}}
++map[string][]int{"a": {1, 2, 3}, "b": {4, 6}}
+`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected: %s\ngot: %v", expected, err)
+	}
+	err = astgen.Verify(path, "y", 1)
+	expected = path + `: declaration of y not found`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected: %s\ngot: %v", expected, err)
+	}
+}
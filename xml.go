@@ -0,0 +1,85 @@
+package astgen
+
+import (
+	"encoding/xml"
+	"errors"
+	"go/ast"
+	"io"
+	"strings"
+)
+
+// BuildXML builds the XML document read from r, decoded by DecodeXML.
+func BuildXML(r io.Reader, opts ...Option) (ast.Node, error) {
+	v, err := DecodeXML(r)
+	if err != nil {
+		return nil, err
+	}
+	return Build(v, opts...)
+}
+
+// DecodeXML decodes the XML document to a generic value of maps, slices and
+// strings. An element is decoded to a map keyed by the attribute names
+// prefixed with "@" and the names of the child elements, with the text
+// content keyed by "#text". The repeated child elements are collected into a
+// slice, and an element with neither attributes nor child elements is decoded
+// to its text content. The document is decoded to a map keyed by the name of
+// the root element.
+func DecodeXML(r io.Reader) (any, error) {
+	d := xml.NewDecoder(r)
+	for {
+		t, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = errors.New("no root element in XML")
+			}
+			return nil, err
+		}
+		if t, ok := t.(xml.StartElement); ok {
+			v, err := decodeXMLElement(d, t)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{t.Name.Local: v}, nil
+		}
+	}
+}
+
+func decodeXMLElement(d *xml.Decoder, start xml.StartElement) (any, error) {
+	m := make(map[string]any)
+	for _, attr := range start.Attr {
+		m["@"+attr.Name.Local] = attr.Value
+	}
+	var text strings.Builder
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			v, err := decodeXMLElement(d, t)
+			if err != nil {
+				return nil, err
+			}
+			switch w := m[t.Name.Local].(type) {
+			case nil:
+				m[t.Name.Local] = v
+			case []any:
+				m[t.Name.Local] = append(w, v)
+			default:
+				m[t.Name.Local] = []any{w, v}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				m["#text"] = s
+			}
+			return m, nil
+		}
+	}
+}
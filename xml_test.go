@@ -0,0 +1,44 @@
+package astgen_test
+
+import (
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildXML(t *testing.T) {
+	got, err := astgen.BuildXML(strings.NewReader(`<?xml version="1.0"?>
+<config version="2">
+	<name>astgen</name>
+	<server host="localhost">8080</server>
+	<tag>a</tag>
+	<tag>b</tag>
+	<empty/>
+</config>`))
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	var sb strings.Builder
+	printer.Fprint(&sb, token.NewFileSet(), got)
+	expected := `map[string]interface {
+}{"config": interface {
+}(map[string]interface {
+}{"@version": interface {
+}("2"), "empty": interface {
+}(""), "name": interface {
+}("astgen"), "server": interface {
+}(map[string]interface {
+}{"#text": interface {
+}("8080"), "@host": interface {
+}("localhost")}), "tag": interface {
+}([]interface {
+}{interface {
+}("a"), interface {
+}("b")})})}`
+	if sb.String() != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, sb.String())
+	}
+}
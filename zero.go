@@ -0,0 +1,32 @@
+package astgen
+
+import (
+	"go/ast"
+	"reflect"
+)
+
+type zeroKey struct {
+	t     reflect.Type
+	elide bool
+}
+
+// buildZero builds the zero value of a struct or an array. The expression is
+// cached per type, and cloned on reuse, because sparse data built with
+// explicit zeros rebuilds the same expressions repeatedly.
+func (b *builder) buildZero(v reflect.Value, elide bool) (ast.Expr, error) {
+	key := zeroKey{v.Type(), elide}
+	if e, ok := b.zeros[key]; ok {
+		b.elide = false
+		return cloneNode(e).(ast.Expr), nil
+	}
+	b.elide = elide
+	e, err := b.buildValueUncached(v)
+	if err != nil {
+		return nil, err
+	}
+	if b.zeros == nil {
+		b.zeros = make(map[zeroKey]ast.Expr)
+	}
+	b.zeros[key] = cloneNode(e).(ast.Expr)
+	return e, nil
+}
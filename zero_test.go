@@ -0,0 +1,96 @@
+package astgen_test
+
+import (
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/itchyny/astgen-go"
+)
+
+func TestBuildZero(t *testing.T) {
+	type T struct {
+		X [2]int
+		Y string
+	}
+	type U struct {
+		S []T
+		M map[string]int
+	}
+	testCases := []struct {
+		name     string
+		src      any
+		expected string
+	}{
+		{
+			name:     "zero struct",
+			src:      T{},
+			expected: `T{}`,
+		},
+		{
+			name:     "zero array",
+			src:      [3]T{},
+			expected: `[3]T{{}, {}, {}}`,
+		},
+		{
+			name:     "sparse slice",
+			src:      []T{{}, {Y: "foo"}, {}, {X: [2]int{0, 1}}, {}},
+			expected: `[]T{{}, {Y: "foo"}, {}, {X: [2]int{0, 1}}, {}}`,
+		},
+		{
+			name:     "zero structs before composite field",
+			src:      U{S: []T{{}, {}}, M: map[string]int{"x": 1}},
+			expected: `U{S: []T{{}, {}}, M: map[string]int{"x": 1}}`,
+		},
+		{
+			name: "zero arrays",
+			src:  []any{[2]int{}, [2]int{}, [2]float64{0, math.Copysign(0, -1)}},
+			expected: `[]interface {
+}{interface {
+}([2]int{0, 0}), interface {
+}([2]int{0, 0}), interface {
+}([2]float64{0.0, -0.0})}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := astgen.Build(tc.src)
+			if err != nil {
+				t.Fatalf("should not return error: %s", err)
+			}
+			var sb strings.Builder
+			printer.Fprint(&sb, token.NewFileSet(), got)
+			if sb.String() != tc.expected {
+				t.Errorf("expected: %s\ngot: %s", tc.expected, sb.String())
+			}
+		})
+	}
+}
+
+func TestBuildZeroNotShared(t *testing.T) {
+	got, err := astgen.Build([]struct{ X int }{{}, {}})
+	if err != nil {
+		t.Fatalf("should not return error: %s", err)
+	}
+	elts := got.(*ast.CompositeLit).Elts
+	if elts[0] == elts[1] {
+		t.Errorf("should not share the zero value nodes")
+	}
+}
+
+func BenchmarkBuildSparse(b *testing.B) {
+	type T struct {
+		X [16]int
+		Y map[string]int
+	}
+	src := make([]T, 1000)
+	src[500].Y = map[string]int{"foo": 1}
+	for i := 0; i < b.N; i++ {
+		if _, err := astgen.Build(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}